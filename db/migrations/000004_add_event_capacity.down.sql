@@ -0,0 +1,7 @@
+DROP INDEX IF EXISTS idx_registrations_event_status;
+
+ALTER TABLE registrations DROP CONSTRAINT IF EXISTS registrations_status_check;
+ALTER TABLE registrations DROP COLUMN IF EXISTS status;
+
+ALTER TABLE events DROP CONSTRAINT IF EXISTS events_capacity_check;
+ALTER TABLE events DROP COLUMN IF EXISTS capacity;
@@ -0,0 +1,8 @@
+ALTER TABLE events ADD COLUMN capacity INTEGER;
+ALTER TABLE events ADD CONSTRAINT events_capacity_check CHECK (capacity > 0);
+
+ALTER TABLE registrations ADD COLUMN status TEXT NOT NULL DEFAULT 'confirmed';
+ALTER TABLE registrations ADD CONSTRAINT registrations_status_check
+    CHECK (status IN ('confirmed', 'waitlisted'));
+
+CREATE INDEX idx_registrations_event_status ON registrations(event_id, status);
@@ -0,0 +1,8 @@
+ALTER TABLE events ADD COLUMN search_vector tsvector
+    GENERATED ALWAYS AS (
+        setweight(to_tsvector('english', coalesce(title, '')), 'A') ||
+        setweight(to_tsvector('english', coalesce(description, '')), 'B') ||
+        setweight(to_tsvector('english', coalesce(location, '')), 'C')
+    ) STORED;
+
+CREATE INDEX idx_events_search_vector ON events USING GIN(search_vector);
@@ -0,0 +1,10 @@
+DELETE FROM registrations WHERE occurrence_date IS NOT NULL;
+ALTER TABLE registrations DROP CONSTRAINT IF EXISTS unique_user_event;
+ALTER TABLE registrations ADD CONSTRAINT unique_user_event UNIQUE(user_id, event_id);
+ALTER TABLE registrations DROP COLUMN IF EXISTS occurrence_date;
+
+DROP TABLE IF EXISTS event_occurrence_overrides;
+
+ALTER TABLE events DROP COLUMN IF EXISTS recurrence_ends_at;
+ALTER TABLE events DROP COLUMN IF EXISTS recurrence_exdates;
+ALTER TABLE events DROP COLUMN IF EXISTS recurrence_rule;
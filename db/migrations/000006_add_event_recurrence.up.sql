@@ -0,0 +1,26 @@
+ALTER TABLE events ADD COLUMN recurrence_rule TEXT;
+ALTER TABLE events ADD COLUMN recurrence_exdates TIMESTAMP[] NOT NULL DEFAULT '{}';
+-- Start of the last occurrence; NULL for single events and open-ended series
+ALTER TABLE events ADD COLUMN recurrence_ends_at TIMESTAMP;
+
+CREATE TABLE IF NOT EXISTS event_occurrence_overrides (
+    id SERIAL PRIMARY KEY,
+    event_id INTEGER NOT NULL,
+    occurrence_date TIMESTAMP NOT NULL,
+    title TEXT,
+    description TEXT,
+    location TEXT,
+    date TIMESTAMP,
+    CONSTRAINT fk_override_event
+        FOREIGN KEY(event_id)
+        REFERENCES events(id)
+        ON DELETE CASCADE,
+    CONSTRAINT unique_event_occurrence
+        UNIQUE(event_id, occurrence_date)
+);
+
+-- NULL occurrence_date means the whole event (or the whole series)
+ALTER TABLE registrations ADD COLUMN occurrence_date TIMESTAMP;
+ALTER TABLE registrations DROP CONSTRAINT unique_user_event;
+ALTER TABLE registrations ADD CONSTRAINT unique_user_event
+    UNIQUE NULLS NOT DISTINCT (user_id, event_id, occurrence_date);
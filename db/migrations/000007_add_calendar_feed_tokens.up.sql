@@ -0,0 +1,2 @@
+-- SHA-256 of the secret token in the user's calendar feed URL
+ALTER TABLE users ADD COLUMN calendar_token_hash TEXT UNIQUE;
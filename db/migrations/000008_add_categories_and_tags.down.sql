@@ -0,0 +1,8 @@
+DROP INDEX IF EXISTS idx_event_tags_tag;
+DROP TABLE IF EXISTS event_tags;
+
+DROP INDEX IF EXISTS idx_events_category_id;
+ALTER TABLE events DROP CONSTRAINT IF EXISTS fk_event_category;
+ALTER TABLE events DROP COLUMN IF EXISTS category_id;
+
+DROP TABLE IF EXISTS categories;
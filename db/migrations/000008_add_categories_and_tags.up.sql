@@ -0,0 +1,26 @@
+CREATE TABLE IF NOT EXISTS categories (
+    id SERIAL PRIMARY KEY,
+    name TEXT NOT NULL UNIQUE,
+    description TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+ALTER TABLE events ADD COLUMN category_id INTEGER;
+ALTER TABLE events ADD CONSTRAINT fk_event_category
+    FOREIGN KEY(category_id)
+    REFERENCES categories(id)
+    ON DELETE SET NULL;
+
+CREATE INDEX idx_events_category_id ON events(category_id);
+
+CREATE TABLE IF NOT EXISTS event_tags (
+    event_id INTEGER NOT NULL,
+    tag TEXT NOT NULL,
+    PRIMARY KEY (event_id, tag),
+    CONSTRAINT fk_event_tag_event
+        FOREIGN KEY(event_id)
+        REFERENCES events(id)
+        ON DELETE CASCADE
+);
+
+CREATE INDEX idx_event_tags_tag ON event_tags(tag);
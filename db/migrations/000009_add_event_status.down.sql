@@ -0,0 +1,8 @@
+DELETE FROM registrations WHERE status = 'cancelled';
+ALTER TABLE registrations DROP CONSTRAINT IF EXISTS registrations_status_check;
+ALTER TABLE registrations ADD CONSTRAINT registrations_status_check
+    CHECK (status IN ('confirmed', 'waitlisted'));
+
+DROP INDEX IF EXISTS idx_events_status_date;
+ALTER TABLE events DROP CONSTRAINT IF EXISTS events_status_check;
+ALTER TABLE events DROP COLUMN IF EXISTS status;
@@ -0,0 +1,12 @@
+-- Existing events were live as soon as they were created, so they start out
+-- published; new events default to draft.
+ALTER TABLE events ADD COLUMN status TEXT NOT NULL DEFAULT 'published';
+ALTER TABLE events ADD CONSTRAINT events_status_check
+    CHECK (status IN ('draft', 'published', 'cancelled'));
+ALTER TABLE events ALTER COLUMN status SET DEFAULT 'draft';
+
+CREATE INDEX idx_events_status_date ON events(status, date);
+
+ALTER TABLE registrations DROP CONSTRAINT registrations_status_check;
+ALTER TABLE registrations ADD CONSTRAINT registrations_status_check
+    CHECK (status IN ('confirmed', 'waitlisted', 'cancelled'));
@@ -0,0 +1,12 @@
+-- Soft-deleted rows cannot be represented without the column
+DELETE FROM events WHERE deleted_at IS NOT NULL;
+DELETE FROM users WHERE deleted_at IS NOT NULL;
+
+DROP INDEX IF EXISTS idx_events_deleted_at;
+DROP INDEX IF EXISTS idx_users_deleted_at;
+
+DROP INDEX IF EXISTS users_email_live_key;
+ALTER TABLE users ADD CONSTRAINT users_email_key UNIQUE (email);
+
+ALTER TABLE events DROP COLUMN IF EXISTS deleted_at;
+ALTER TABLE users DROP COLUMN IF EXISTS deleted_at;
@@ -0,0 +1,10 @@
+ALTER TABLE users ADD COLUMN deleted_at TIMESTAMP;
+ALTER TABLE events ADD COLUMN deleted_at TIMESTAMP;
+
+-- Emails only need to be unique among live accounts, so the address of a
+-- deleted user can be taken by a new signup
+ALTER TABLE users DROP CONSTRAINT users_email_key;
+CREATE UNIQUE INDEX users_email_live_key ON users(email) WHERE deleted_at IS NULL;
+
+CREATE INDEX idx_users_deleted_at ON users(deleted_at) WHERE deleted_at IS NOT NULL;
+CREATE INDEX idx_events_deleted_at ON events(deleted_at) WHERE deleted_at IS NOT NULL;
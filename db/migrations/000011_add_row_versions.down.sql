@@ -0,0 +1,4 @@
+ALTER TABLE users DROP COLUMN IF EXISTS updated_at;
+ALTER TABLE users DROP COLUMN IF EXISTS version;
+
+ALTER TABLE events DROP COLUMN IF EXISTS version;
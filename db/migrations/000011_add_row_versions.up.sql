@@ -0,0 +1,5 @@
+-- version is bumped on every update and backs the ETag of each row
+ALTER TABLE events ADD COLUMN version INTEGER NOT NULL DEFAULT 1;
+
+ALTER TABLE users ADD COLUMN version INTEGER NOT NULL DEFAULT 1;
+ALTER TABLE users ADD COLUMN updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP;
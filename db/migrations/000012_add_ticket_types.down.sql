@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS idx_registrations_ticket_type_id;
+ALTER TABLE registrations DROP CONSTRAINT IF EXISTS fk_registration_ticket_type;
+ALTER TABLE registrations DROP COLUMN IF EXISTS ticket_type_id;
+
+DROP TABLE IF EXISTS ticket_types;
@@ -0,0 +1,31 @@
+CREATE TABLE IF NOT EXISTS ticket_types (
+    id SERIAL PRIMARY KEY,
+    event_id INTEGER NOT NULL,
+    name TEXT NOT NULL,
+    price_cents INTEGER NOT NULL DEFAULT 0 CHECK (price_cents >= 0),
+    currency CHAR(3) NOT NULL,
+    -- NULL quantity means the type is only limited by the event capacity
+    quantity INTEGER CHECK (quantity > 0),
+    sales_start TIMESTAMP,
+    sales_end TIMESTAMP,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT fk_ticket_type_event
+        FOREIGN KEY(event_id)
+        REFERENCES events(id)
+        ON DELETE CASCADE,
+    CONSTRAINT unique_event_ticket_type_name
+        UNIQUE(event_id, name),
+    CONSTRAINT ticket_type_sales_window_check
+        CHECK (sales_start IS NULL OR sales_end IS NULL OR sales_start < sales_end)
+);
+
+CREATE INDEX idx_ticket_types_event_id ON ticket_types(event_id);
+
+-- Ticket types that have been sold cannot be deleted
+ALTER TABLE registrations ADD COLUMN ticket_type_id INTEGER;
+ALTER TABLE registrations ADD CONSTRAINT fk_registration_ticket_type
+    FOREIGN KEY(ticket_type_id)
+    REFERENCES ticket_types(id)
+    ON DELETE RESTRICT;
+
+CREATE INDEX idx_registrations_ticket_type_id ON registrations(ticket_type_id);
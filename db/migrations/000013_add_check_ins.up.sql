@@ -0,0 +1,19 @@
+-- One check-in per registration, or per occurrence for series registrations
+-- of recurring events
+CREATE TABLE IF NOT EXISTS check_ins (
+    id SERIAL PRIMARY KEY,
+    registration_id INTEGER NOT NULL,
+    occurrence_date TIMESTAMP,
+    checked_in_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    checked_in_by INTEGER,
+    CONSTRAINT fk_check_in_registration
+        FOREIGN KEY(registration_id)
+        REFERENCES registrations(id)
+        ON DELETE CASCADE,
+    CONSTRAINT fk_check_in_user
+        FOREIGN KEY(checked_in_by)
+        REFERENCES users(id)
+        ON DELETE SET NULL,
+    CONSTRAINT unique_registration_check_in
+        UNIQUE NULLS NOT DISTINCT (registration_id, occurrence_date)
+);
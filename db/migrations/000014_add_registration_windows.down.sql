@@ -0,0 +1,4 @@
+ALTER TABLE events DROP CONSTRAINT IF EXISTS events_registration_window_check;
+ALTER TABLE events DROP COLUMN IF EXISTS unregistration_closes_at;
+ALTER TABLE events DROP COLUMN IF EXISTS registration_closes_at;
+ALTER TABLE events DROP COLUMN IF EXISTS registration_opens_at;
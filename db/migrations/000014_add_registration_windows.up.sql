@@ -0,0 +1,9 @@
+-- NULL means no restriction beyond the defaults: registration and
+-- unregistration both close when the event (or occurrence) starts
+ALTER TABLE events ADD COLUMN registration_opens_at TIMESTAMP;
+ALTER TABLE events ADD COLUMN registration_closes_at TIMESTAMP;
+ALTER TABLE events ADD COLUMN unregistration_closes_at TIMESTAMP;
+
+ALTER TABLE events ADD CONSTRAINT events_registration_window_check
+    CHECK (registration_opens_at IS NULL OR registration_closes_at IS NULL
+        OR registration_opens_at < registration_closes_at);
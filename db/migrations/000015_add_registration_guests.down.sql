@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS registration_guests;
+ALTER TABLE registrations DROP COLUMN IF EXISTS party_size;
@@ -0,0 +1,17 @@
+-- party_size is the registrant plus their guests; it is what a registration
+-- takes out of the event's capacity
+ALTER TABLE registrations ADD COLUMN party_size INTEGER NOT NULL DEFAULT 1
+    CHECK (party_size >= 1);
+
+CREATE TABLE IF NOT EXISTS registration_guests (
+    id SERIAL PRIMARY KEY,
+    registration_id INTEGER NOT NULL,
+    name TEXT NOT NULL,
+    email TEXT,
+    CONSTRAINT fk_guest_registration
+        FOREIGN KEY(registration_id)
+        REFERENCES registrations(id)
+        ON DELETE CASCADE
+);
+
+CREATE INDEX idx_registration_guests_registration_id ON registration_guests(registration_id);
@@ -0,0 +1,33 @@
+CREATE TABLE IF NOT EXISTS event_questions (
+    id SERIAL PRIMARY KEY,
+    event_id INTEGER NOT NULL,
+    label TEXT NOT NULL,
+    type TEXT NOT NULL CHECK (type IN ('text', 'number', 'single_choice', 'multi_choice')),
+    required BOOLEAN NOT NULL DEFAULT FALSE,
+    -- Choices for single_choice and multi_choice questions, as a JSON array
+    options JSONB NOT NULL DEFAULT '[]',
+    position INTEGER NOT NULL DEFAULT 0,
+    CONSTRAINT fk_question_event
+        FOREIGN KEY(event_id)
+        REFERENCES events(id)
+        ON DELETE CASCADE
+);
+
+CREATE INDEX idx_event_questions_event_id ON event_questions(event_id, position);
+
+CREATE TABLE IF NOT EXISTS registration_answers (
+    registration_id INTEGER NOT NULL,
+    question_id INTEGER NOT NULL,
+    value JSONB NOT NULL,
+    PRIMARY KEY (registration_id, question_id),
+    CONSTRAINT fk_answer_registration
+        FOREIGN KEY(registration_id)
+        REFERENCES registrations(id)
+        ON DELETE CASCADE,
+    CONSTRAINT fk_answer_question
+        FOREIGN KEY(question_id)
+        REFERENCES event_questions(id)
+        ON DELETE CASCADE
+);
+
+CREATE INDEX idx_registration_answers_question_id ON registration_answers(question_id);
@@ -0,0 +1,9 @@
+ALTER TABLE registrations DROP COLUMN IF EXISTS reviewed_by;
+ALTER TABLE registrations DROP COLUMN IF EXISTS reviewed_at;
+
+DELETE FROM registrations WHERE status IN ('pending', 'rejected');
+ALTER TABLE registrations DROP CONSTRAINT IF EXISTS registrations_status_check;
+ALTER TABLE registrations ADD CONSTRAINT registrations_status_check
+    CHECK (status IN ('confirmed', 'waitlisted', 'cancelled'));
+
+ALTER TABLE events DROP COLUMN IF EXISTS requires_approval;
@@ -0,0 +1,10 @@
+ALTER TABLE events ADD COLUMN requires_approval BOOLEAN NOT NULL DEFAULT FALSE;
+
+-- Applications to approval-only events start out pending until the organizer
+-- approves or rejects them
+ALTER TABLE registrations DROP CONSTRAINT registrations_status_check;
+ALTER TABLE registrations ADD CONSTRAINT registrations_status_check
+    CHECK (status IN ('pending', 'confirmed', 'waitlisted', 'rejected', 'cancelled'));
+
+ALTER TABLE registrations ADD COLUMN reviewed_at TIMESTAMP;
+ALTER TABLE registrations ADD COLUMN reviewed_by INTEGER REFERENCES users(id) ON DELETE SET NULL;
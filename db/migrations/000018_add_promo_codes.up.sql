@@ -0,0 +1,49 @@
+CREATE TABLE IF NOT EXISTS promo_codes (
+    id SERIAL PRIMARY KEY,
+    event_id INTEGER NOT NULL,
+    -- Codes are stored upper-case and matched case-insensitively
+    code TEXT NOT NULL,
+    discount_type TEXT NOT NULL CHECK (discount_type IN ('percent', 'fixed')),
+    -- A percentage for percent codes, an amount in cents for fixed ones
+    discount_value INTEGER NOT NULL CHECK (discount_value > 0),
+    max_uses INTEGER CHECK (max_uses > 0),
+    max_uses_per_user INTEGER CHECK (max_uses_per_user > 0),
+    expires_at TIMESTAMP,
+    -- An empty list means the code applies to every ticket type
+    ticket_type_ids INTEGER[] NOT NULL DEFAULT '{}',
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT fk_promo_code_event
+        FOREIGN KEY(event_id)
+        REFERENCES events(id)
+        ON DELETE CASCADE,
+    CONSTRAINT unique_event_promo_code
+        UNIQUE(event_id, code),
+    CONSTRAINT promo_code_percent_check
+        CHECK (discount_type <> 'percent' OR discount_value <= 100)
+);
+
+-- Redemptions go away with their registration, which frees the use. Codes
+-- that have been redeemed cannot be deleted.
+CREATE TABLE IF NOT EXISTS promo_redemptions (
+    id SERIAL PRIMARY KEY,
+    promo_code_id INTEGER NOT NULL,
+    registration_id INTEGER NOT NULL UNIQUE,
+    user_id INTEGER NOT NULL,
+    price_cents INTEGER NOT NULL,
+    discount_cents INTEGER NOT NULL CHECK (discount_cents >= 0),
+    redeemed_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT fk_redemption_promo_code
+        FOREIGN KEY(promo_code_id)
+        REFERENCES promo_codes(id)
+        ON DELETE RESTRICT,
+    CONSTRAINT fk_redemption_registration
+        FOREIGN KEY(registration_id)
+        REFERENCES registrations(id)
+        ON DELETE CASCADE,
+    CONSTRAINT fk_redemption_user
+        FOREIGN KEY(user_id)
+        REFERENCES users(id)
+        ON DELETE CASCADE
+);
+
+CREATE INDEX idx_promo_redemptions_promo_code_id ON promo_redemptions(promo_code_id, user_id);
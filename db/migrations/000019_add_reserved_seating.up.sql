@@ -0,0 +1,95 @@
+CREATE TABLE IF NOT EXISTS venues (
+    id SERIAL PRIMARY KEY,
+    name TEXT NOT NULL,
+    user_id INTEGER,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT fk_venue_user
+        FOREIGN KEY(user_id)
+        REFERENCES users(id)
+        ON DELETE SET NULL
+);
+
+CREATE TABLE IF NOT EXISTS venue_sections (
+    id SERIAL PRIMARY KEY,
+    venue_id INTEGER NOT NULL,
+    name TEXT NOT NULL,
+    position INTEGER NOT NULL DEFAULT 0,
+    CONSTRAINT fk_section_venue
+        FOREIGN KEY(venue_id)
+        REFERENCES venues(id)
+        ON DELETE CASCADE,
+    CONSTRAINT unique_venue_section_name
+        UNIQUE(venue_id, name)
+);
+
+CREATE TABLE IF NOT EXISTS venue_rows (
+    id SERIAL PRIMARY KEY,
+    section_id INTEGER NOT NULL,
+    label TEXT NOT NULL,
+    position INTEGER NOT NULL DEFAULT 0,
+    CONSTRAINT fk_row_section
+        FOREIGN KEY(section_id)
+        REFERENCES venue_sections(id)
+        ON DELETE CASCADE,
+    CONSTRAINT unique_section_row_label
+        UNIQUE(section_id, label)
+);
+
+CREATE TABLE IF NOT EXISTS venue_seats (
+    id SERIAL PRIMARY KEY,
+    row_id INTEGER NOT NULL,
+    label TEXT NOT NULL,
+    position INTEGER NOT NULL DEFAULT 0,
+    CONSTRAINT fk_seat_row
+        FOREIGN KEY(row_id)
+        REFERENCES venue_rows(id)
+        ON DELETE CASCADE,
+    CONSTRAINT unique_row_seat_label
+        UNIQUE(row_id, label)
+);
+
+-- Venues in use by an event cannot be deleted
+ALTER TABLE events ADD COLUMN venue_id INTEGER;
+ALTER TABLE events ADD CONSTRAINT fk_event_venue
+    FOREIGN KEY(venue_id)
+    REFERENCES venues(id)
+    ON DELETE RESTRICT;
+
+-- A seat of an event is either held for a few minutes while its holder
+-- registers, or booked by a registration. The unique constraint is what
+-- guarantees a seat is never sold twice; expired holds are removed before a
+-- seat is taken again.
+CREATE TABLE IF NOT EXISTS event_seats (
+    id SERIAL PRIMARY KEY,
+    event_id INTEGER NOT NULL,
+    seat_id INTEGER NOT NULL,
+    occurrence_date TIMESTAMP,
+    user_id INTEGER NOT NULL,
+    registration_id INTEGER,
+    held_until TIMESTAMP,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT fk_event_seat_event
+        FOREIGN KEY(event_id)
+        REFERENCES events(id)
+        ON DELETE CASCADE,
+    CONSTRAINT fk_event_seat_seat
+        FOREIGN KEY(seat_id)
+        REFERENCES venue_seats(id)
+        ON DELETE CASCADE,
+    CONSTRAINT fk_event_seat_user
+        FOREIGN KEY(user_id)
+        REFERENCES users(id)
+        ON DELETE CASCADE,
+    CONSTRAINT fk_event_seat_registration
+        FOREIGN KEY(registration_id)
+        REFERENCES registrations(id)
+        ON DELETE CASCADE,
+    CONSTRAINT unique_event_seat
+        UNIQUE NULLS NOT DISTINCT (event_id, seat_id, occurrence_date),
+    -- Held seats expire, booked seats belong to a registration
+    CONSTRAINT event_seat_state_check
+        CHECK ((registration_id IS NULL) <> (held_until IS NULL))
+);
+
+CREATE INDEX idx_event_seats_registration_id ON event_seats(registration_id);
+CREATE INDEX idx_event_seats_user ON event_seats(event_id, user_id);
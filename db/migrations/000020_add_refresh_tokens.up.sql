@@ -0,0 +1,32 @@
+-- Bumped to invalidate every access and refresh token issued to the user
+ALTER TABLE users ADD COLUMN token_version INTEGER NOT NULL DEFAULT 0;
+
+-- Refresh tokens are single-use. Each refresh replaces the token with a new
+-- one in the same family, so a token presented twice reveals that it was
+-- copied and the whole family is revoked.
+CREATE TABLE IF NOT EXISTS refresh_tokens (
+    id SERIAL PRIMARY KEY,
+    user_id INTEGER NOT NULL,
+    family_id TEXT NOT NULL,
+    -- SHA-256 of the token handed to the client
+    token_hash TEXT NOT NULL UNIQUE,
+    -- users.token_version when the token was issued
+    token_version INTEGER NOT NULL,
+    expires_at TIMESTAMP NOT NULL,
+    used_at TIMESTAMP,
+    revoked_at TIMESTAMP,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT fk_refresh_token_user
+        FOREIGN KEY(user_id)
+        REFERENCES users(id)
+        ON DELETE CASCADE
+);
+
+CREATE INDEX idx_refresh_tokens_family ON refresh_tokens(family_id);
+
+-- Access tokens revoked before they expire, by their jti. Rows can go once
+-- the token would have expired anyway.
+CREATE TABLE IF NOT EXISTS revoked_tokens (
+    jti TEXT PRIMARY KEY,
+    expires_at TIMESTAMP NOT NULL
+);
@@ -0,0 +1,6 @@
+ALTER TABLE users ADD COLUMN email_verified_at TIMESTAMP;
+-- When the last verification email went out, to throttle resends
+ALTER TABLE users ADD COLUMN verification_sent_at TIMESTAMP;
+
+-- Accounts from before verification keep working
+UPDATE users SET email_verified_at = NOW();
@@ -0,0 +1,50 @@
+CREATE TABLE IF NOT EXISTS roles (
+    name TEXT PRIMARY KEY,
+    description TEXT NOT NULL DEFAULT '',
+    -- Built-in roles cannot be changed or deleted through the API
+    builtin BOOLEAN NOT NULL DEFAULT FALSE,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Permissions are from the catalogue in models/permission.go
+CREATE TABLE IF NOT EXISTS role_permissions (
+    role TEXT NOT NULL,
+    permission TEXT NOT NULL,
+    PRIMARY KEY (role, permission),
+    CONSTRAINT fk_role_permission_role
+        FOREIGN KEY(role)
+        REFERENCES roles(name)
+        ON DELETE CASCADE
+        ON UPDATE CASCADE
+);
+
+INSERT INTO roles (name, description, builtin) VALUES
+    ('admin', 'Full access to every event and user', TRUE),
+    ('user', 'Manages their own events and registrations', TRUE);
+
+INSERT INTO role_permissions (role, permission)
+SELECT 'admin', permission FROM unnest(ARRAY[
+    'events:read:any',
+    'events:update:any',
+    'events:delete:any',
+    'registrations:read:any',
+    'registrations:review:any',
+    'registrations:check_in:any',
+    'users:read:any',
+    'users:update:any',
+    'users:delete:any',
+    'users:assign_role',
+    'roles:manage',
+    'categories:manage',
+    'venues:delete:any'
+]) AS permission;
+
+-- Keep any role already handed out, without permissions
+INSERT INTO roles (name)
+SELECT DISTINCT role FROM users
+ON CONFLICT (name) DO NOTHING;
+
+ALTER TABLE users ADD CONSTRAINT fk_user_role
+    FOREIGN KEY(role)
+    REFERENCES roles(name)
+    ON UPDATE CASCADE;
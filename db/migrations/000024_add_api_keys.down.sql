@@ -0,0 +1,3 @@
+DELETE FROM role_permissions WHERE permission = 'service_accounts:manage';
+DROP TABLE IF EXISTS api_keys;
+ALTER TABLE users DROP COLUMN IF EXISTS service_account;
@@ -0,0 +1,25 @@
+-- Service accounts are users that integrations act as. They cannot log in
+-- with a password and authenticate with API keys only.
+ALTER TABLE users ADD COLUMN service_account BOOLEAN NOT NULL DEFAULT FALSE;
+
+CREATE TABLE IF NOT EXISTS api_keys (
+    id SERIAL PRIMARY KEY,
+    user_id INTEGER NOT NULL,
+    name TEXT NOT NULL,
+    -- Shown in listings to tell keys apart; the secret itself is not kept
+    prefix TEXT NOT NULL,
+    -- SHA-256 of the whole key
+    key_hash TEXT NOT NULL UNIQUE,
+    scopes TEXT[] NOT NULL DEFAULT '{}',
+    expires_at TIMESTAMP NOT NULL,
+    last_used_at TIMESTAMP,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT fk_api_key_user
+        FOREIGN KEY(user_id)
+        REFERENCES users(id)
+        ON DELETE CASCADE
+);
+
+CREATE INDEX idx_api_keys_user_id ON api_keys(user_id);
+
+INSERT INTO role_permissions (role, permission) VALUES ('admin', 'service_accounts:manage');
@@ -0,0 +1,54 @@
+package jobs
+
+import (
+	"time"
+
+	"example.com/event-booking-api/models"
+	"example.com/event-booking-api/utils"
+)
+
+// StartPurgeJob periodically hard-deletes events and users that have been
+// soft-deleted for longer than the retention period, along with expired
+// tokens. The first run happens immediately; the job stops when the process
+// exits. A non-positive interval disables it.
+func StartPurgeJob(retention, interval time.Duration) {
+	if interval <= 0 {
+		utils.Logger.Warn("Purge job disabled", "interval", interval.String())
+		return
+	}
+	utils.Logger.Info("Starting purge job", "retention", retention.String(), "interval", interval.String())
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			purgeDeleted(retention)
+			<-ticker.C
+		}
+	}()
+}
+
+func purgeDeleted(retention time.Duration) {
+	before := time.Now().Add(-retention)
+	result, err := models.PurgeDeleted(before)
+	if err != nil {
+		utils.Logger.Error("Purge job failed", "error", err)
+		return
+	}
+	if result.Events > 0 || result.Users > 0 {
+		utils.Logger.Info("Purged deleted records",
+			"events", result.Events,
+			"users", result.Users,
+			"deleted_before", before)
+	}
+
+	tokens, err := models.PurgeExpiredTokens(time.Now())
+	if err != nil {
+		utils.Logger.Error("Purge of expired tokens failed", "error", err)
+		return
+	}
+	if tokens > 0 {
+		utils.Logger.Info("Purged expired tokens", "tokens", tokens)
+	}
+}
@@ -0,0 +1,29 @@
+package mailer
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"time"
+)
+
+// FileMailer writes each message to its own .eml file in Dir instead of
+// sending it, for development.
+type FileMailer struct {
+	Dir  string
+	From string
+
+	count atomic.Int64
+}
+
+func (m *FileMailer) Send(msg Message) error {
+	err := os.MkdirAll(m.Dir, 0o755)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	name := fmt.Sprintf("%s-%d.eml", now.UTC().Format("20060102T150405.000000000"), m.count.Add(1))
+	return os.WriteFile(filepath.Join(m.Dir, name), msg.format(m.From, now), 0o600)
+}
@@ -0,0 +1,80 @@
+package mailer
+
+import (
+	"bytes"
+	"fmt"
+	"mime"
+	"strings"
+	"time"
+
+	"example.com/event-booking-api/utils"
+)
+
+// Message is a plain-text email.
+type Message struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+// Mailer delivers email.
+type Mailer interface {
+	Send(msg Message) error
+}
+
+// Default is the mailer the application sends through, set by InitMailer.
+var Default Mailer
+
+// InitMailer sets Default from the environment. MAIL_DRIVER picks the
+// implementation: "smtp" for real delivery, or "file" to write each message
+// to MAIL_DIR for development. It must be set, so that a deployment without
+// mail configuration does not quietly write password reset links to disk.
+func InitMailer() {
+	from := utils.GetEnvString("MAIL_FROM", "Event Booking <no-reply@localhost>")
+	driver := utils.GetEnvString("MAIL_DRIVER", "")
+
+	switch driver {
+	case "smtp":
+		Default = &SMTPMailer{
+			Host:     utils.GetEnvString("SMTP_HOST", "localhost"),
+			Port:     utils.GetEnvInt("SMTP_PORT", 587),
+			Username: utils.GetEnvString("SMTP_USERNAME", ""),
+			Password: utils.GetEnvString("SMTP_PASSWORD", ""),
+			From:     from,
+		}
+	case "file":
+		dir := utils.GetEnvString("MAIL_DIR", "tmp/mail")
+		utils.Logger.Warn("Mail is written to files, not delivered", "dir", dir)
+		Default = &FileMailer{
+			Dir:  dir,
+			From: from,
+		}
+	case "":
+		panic("MAIL_DRIVER environment variable not set")
+	default:
+		panic("Unknown MAIL_DRIVER: " + driver)
+	}
+
+	utils.Logger.Info("Mailer initialized", "driver", driver)
+}
+
+// format renders the message in RFC 5322 form.
+func (m Message) format(from string, date time.Time) []byte {
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "From: %s\r\n", headerValue(from))
+	fmt.Fprintf(&b, "To: %s\r\n", headerValue(m.To))
+	fmt.Fprintf(&b, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", headerValue(m.Subject)))
+	fmt.Fprintf(&b, "Date: %s\r\n", date.Format(time.RFC1123Z))
+	b.WriteString("MIME-Version: 1.0\r\n")
+	b.WriteString("Content-Type: text/plain; charset=UTF-8\r\n")
+	b.WriteString("Content-Transfer-Encoding: 8bit\r\n")
+	b.WriteString("\r\n")
+	b.WriteString(strings.ReplaceAll(strings.ReplaceAll(m.Body, "\r\n", "\n"), "\n", "\r\n"))
+	return b.Bytes()
+}
+
+// headerValue drops line breaks, which would otherwise let a value add
+// headers of its own.
+func headerValue(value string) string {
+	return strings.NewReplacer("\r", "", "\n", "").Replace(value)
+}
@@ -1,7 +1,12 @@
 package main
 
 import (
+	"time"
+
 	"example.com/event-booking-api/db"
+	"example.com/event-booking-api/jobs"
+	"example.com/event-booking-api/mailer"
+	"example.com/event-booking-api/models"
 	"example.com/event-booking-api/routes"
 	"example.com/event-booking-api/utils"
 	"github.com/gin-gonic/gin"
@@ -18,6 +23,30 @@ func main() {
 	utils.Logger.Info("Starting Event Booking API")
 
 	db.InitDB()
+	mailer.InitMailer()
+
+	retentionDays := utils.GetEnvInt("SOFT_DELETE_RETENTION_DAYS", 30)
+	purgeIntervalMinutes := utils.GetEnvInt("PURGE_INTERVAL_MINUTES", 60)
+	jobs.StartPurgeJob(
+		time.Duration(retentionDays)*24*time.Hour,
+		time.Duration(purgeIntervalMinutes)*time.Minute)
+
+	accessTokenMinutes := utils.GetEnvInt("ACCESS_TOKEN_MINUTES", 15)
+	utils.AccessTokenDuration = time.Duration(accessTokenMinutes) * time.Minute
+	refreshTokenDays := utils.GetEnvInt("REFRESH_TOKEN_DAYS", 30)
+	models.RefreshTokenDuration = time.Duration(refreshTokenDays) * 24 * time.Hour
+
+	passwordResetMinutes := utils.GetEnvInt("PASSWORD_RESET_MINUTES", 60)
+	models.PasswordResetDuration = time.Duration(passwordResetMinutes) * time.Minute
+
+	verificationHours := utils.GetEnvInt("EMAIL_VERIFICATION_HOURS", 24)
+	models.EmailVerificationDuration = time.Duration(verificationHours) * time.Hour
+	resendMinutes := utils.GetEnvInt("VERIFICATION_RESEND_MINUTES", 5)
+	models.VerificationResendInterval = time.Duration(resendMinutes) * time.Minute
+
+	seatHoldMinutes := utils.GetEnvInt("SEAT_HOLD_MINUTES", 10)
+	models.SeatHoldDuration = time.Duration(seatHoldMinutes) * time.Minute
+
 	server := gin.New()
 	server.Use(gin.Recovery())
 
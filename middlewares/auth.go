@@ -1,14 +1,24 @@
 package middlewares
 
 import (
+	"errors"
 	"net/http"
 	"strings"
 
+	"example.com/event-booking-api/models"
 	"example.com/event-booking-api/utils"
 	"github.com/gin-gonic/gin"
 )
 
+// Authenticate accepts either a Bearer access token in the Authorization
+// header or an API key in the X-API-Key header.
 func Authenticate(c *gin.Context) {
+	apiKey := c.GetHeader("X-API-Key")
+	if apiKey != "" {
+		authenticateAPIKey(c, apiKey)
+		return
+	}
+
 	authHeader := c.GetHeader("Authorization")
 	if authHeader == "" {
 		c.AbortWithStatusJSON(
@@ -20,7 +30,7 @@ func Authenticate(c *gin.Context) {
 	}
 
 	tokenString := strings.TrimPrefix(authHeader, "Bearer ")
-	userID, role, err := utils.VerifyToken(tokenString)
+	claims, err := utils.VerifyToken(tokenString)
 	if err != nil {
 		c.AbortWithStatusJSON(
 			http.StatusUnauthorized,
@@ -30,49 +40,151 @@ func Authenticate(c *gin.Context) {
 		return
 	}
 
-	c.Set("userID", userID)
-	c.Set("role", role)
+	revoked, err := models.IsAccessTokenRevoked(claims)
+	if err != nil {
+		c.AbortWithStatusJSON(
+			http.StatusInternalServerError,
+			gin.H{
+				"error": "Failed to verify token",
+			})
+		return
+	}
+	if revoked {
+		c.AbortWithStatusJSON(
+			http.StatusUnauthorized,
+			gin.H{
+				"error": "Token has been revoked",
+			})
+		return
+	}
+
+	if !setIdentity(c, claims.UserID, claims.Role) {
+		return
+	}
+	c.Set("tokenID", claims.TokenID)
+	c.Set("tokenExpiresAt", claims.ExpiresAt)
 	c.Next()
 }
 
-func RequireRole(c *gin.Context, allowedRoles ...string) error {
-	role, exists := c.Get("role")
-	if !exists {
+func authenticateAPIKey(c *gin.Context, apiKey string) {
+	identity, err := models.AuthenticateAPIKey(apiKey)
+	if errors.Is(err, models.ErrInvalidAPIKey) {
 		c.AbortWithStatusJSON(
-			http.StatusForbidden,
+			http.StatusUnauthorized,
+			gin.H{
+				"error": "Invalid API key",
+			})
+		return
+	}
+	if err != nil {
+		c.AbortWithStatusJSON(
+			http.StatusInternalServerError,
 			gin.H{
-				"error": "Role not found in context",
+				"error": "Failed to verify API key",
 			})
-		return nil
+		return
 	}
 
-	roleStr, ok := role.(string)
-	if !ok {
+	if !setIdentity(c, identity.UserID, identity.Role) {
+		return
+	}
+	c.Set("apiKey", identity)
+	c.Next()
+}
+
+// setIdentity stores who the request acts as, with the permissions of their
+// role. It aborts the request and returns false if they cannot be loaded.
+func setIdentity(c *gin.Context, userID int64, role string) bool {
+	permissions, err := models.GetRolePermissions(role)
+	if err != nil {
 		c.AbortWithStatusJSON(
-			http.StatusForbidden,
+			http.StatusInternalServerError,
 			gin.H{
-				"error": "Invalid role type",
+				"error": "Failed to load permissions",
 			})
-		return nil
+		return false
 	}
 
-	for _, allowedRole := range allowedRoles {
-		if roleStr == allowedRole {
-			return nil
+	c.Set("userID", userID)
+	c.Set("role", role)
+	c.Set("permissions", permissions)
+	return true
+}
+
+// HasPermission reports whether the authenticated user's role grants the
+// permission.
+func HasPermission(c *gin.Context, permission string) bool {
+	permissions, _ := c.Get("permissions")
+	set, ok := permissions.(models.PermissionSet)
+	return ok && set.Has(permission)
+}
+
+// RequirePermission lets only users whose role grants the permission
+// through. It must run after Authenticate.
+func RequirePermission(permission string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !HasPermission(c, permission) {
+			c.AbortWithStatusJSON(
+				http.StatusForbidden,
+				gin.H{
+					"error": "Insufficient permissions",
+				})
+			return
 		}
+		c.Next()
 	}
-
-	c.AbortWithStatusJSON(
-		http.StatusForbidden,
-		gin.H{
-			"error": "Insufficient permissions",
-		})
-	return nil
 }
 
-func AuthorizeAdmin(c *gin.Context) {
-	err := RequireRole(c, "admin")
+// RequireVerifiedEmail lets only users who verified their email address
+// through. It must run after Authenticate.
+func RequireVerifiedEmail(c *gin.Context) {
+	verified, err := models.IsEmailVerified(c.GetInt64("userID"))
 	if err != nil {
+		c.AbortWithStatusJSON(
+			http.StatusInternalServerError,
+			gin.H{
+				"error": "Failed to check email verification",
+			})
+		return
+	}
+	if !verified {
+		c.AbortWithStatusJSON(
+			http.StatusForbidden,
+			gin.H{
+				"error": "Please verify your email address first",
+				"code":  "email_not_verified",
+			})
+		return
+	}
+	c.Next()
+}
+
+// RequireScope lets requests made with an API key through only if the key
+// has the scope. Requests with an access token are not limited by scopes.
+func RequireScope(scope string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		identity, ok := c.Get("apiKey")
+		if ok && !identity.(*models.APIKeyIdentity).HasScope(scope) {
+			c.AbortWithStatusJSON(
+				http.StatusForbidden,
+				gin.H{
+					"error": "API key is missing the " + scope + " scope",
+				})
+			return
+		}
+		c.Next()
+	}
+}
+
+// DenyAPIKeys keeps API keys away from routes that need the user to be
+// signed in themselves, such as managing credentials.
+func DenyAPIKeys(c *gin.Context) {
+	if _, ok := c.Get("apiKey"); ok {
+		c.AbortWithStatusJSON(
+			http.StatusForbidden,
+			gin.H{
+				"error": "This action cannot be performed with an API key",
+			})
 		return
 	}
 	c.Next()
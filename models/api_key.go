@@ -0,0 +1,224 @@
+package models
+
+import (
+	"database/sql"
+	"errors"
+	"slices"
+	"time"
+
+	"example.com/event-booking-api/db"
+	"example.com/event-booking-api/utils"
+	"github.com/lib/pq"
+)
+
+// API key scopes. A request made with an API key may only use routes whose
+// scope the key has, on top of what the key owner's role allows.
+const (
+	ScopeEventsRead         = "events:read"
+	ScopeEventsWrite        = "events:write"
+	ScopeRegistrationsRead  = "registrations:read"
+	ScopeRegistrationsWrite = "registrations:write"
+	ScopeCheckIn            = "check_in"
+	ScopeUsersRead          = "users:read"
+	ScopeUsersWrite         = "users:write"
+	ScopeAdmin              = "admin"
+)
+
+// Scopes lists every scope a key can be given.
+var Scopes = []string{
+	ScopeEventsRead,
+	ScopeEventsWrite,
+	ScopeRegistrationsRead,
+	ScopeRegistrationsWrite,
+	ScopeCheckIn,
+	ScopeUsersRead,
+	ScopeUsersWrite,
+	ScopeAdmin,
+}
+
+// APIKeyPrefix starts every API key, so leaked keys are easy to spot.
+const APIKeyPrefix = "ebk_"
+
+// DefaultAPIKeyLifetime is how long a key created without an expiry lasts.
+var DefaultAPIKeyLifetime = 365 * 24 * time.Hour
+
+var (
+	ErrInvalidAPIKey       = errors.New("API key is invalid or expired")
+	ErrUnknownAPIKey       = errors.New("API key does not exist")
+	ErrUnknownScope        = errors.New("scope is not known")
+	ErrInvalidAPIKeyExpiry = errors.New("API key expiry must be in the future")
+)
+
+// APIKey is a long-lived credential of a user or service account. Only a
+// hash of the secret is stored; the key is shown once, when it is created.
+type APIKey struct {
+	ID         int64      `json:"id"`
+	UserID     int64      `json:"user_id"`
+	Name       string     `json:"name" binding:"required,max=100"`
+	Prefix     string     `json:"prefix"`
+	Scopes     []string   `json:"scopes" binding:"required,min=1"`
+	ExpiresAt  *time.Time `json:"expires_at"`
+	LastUsedAt *time.Time `json:"last_used_at"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
+
+// APIKeyIdentity is who a request made with an API key acts as.
+type APIKeyIdentity struct {
+	KeyID  int64
+	UserID int64
+	Role   string
+	Scopes []string
+}
+
+func (i *APIKeyIdentity) HasScope(scope string) bool {
+	return slices.Contains(i.Scopes, scope)
+}
+
+// apiKeyColumns lists the columns scanned by scanAPIKey, in order.
+const apiKeyColumns = "id, user_id, name, prefix, scopes, expires_at, last_used_at, created_at"
+
+func scanAPIKey(row rowScanner) (*APIKey, error) {
+	var k APIKey
+	var expiresAt time.Time
+	var lastUsedAt sql.NullTime
+	err := row.Scan(&k.ID, &k.UserID, &k.Name, &k.Prefix, pq.Array(&k.Scopes), &expiresAt, &lastUsedAt, &k.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	k.ExpiresAt = &expiresAt
+	if lastUsedAt.Valid {
+		k.LastUsedAt = &lastUsedAt.Time
+	}
+	return &k, nil
+}
+
+// Save creates the key and returns its secret, which cannot be retrieved
+// again.
+func (k *APIKey) Save() (string, error) {
+	for _, scope := range k.Scopes {
+		if !slices.Contains(Scopes, scope) {
+			return "", ErrUnknownScope
+		}
+	}
+	slices.Sort(k.Scopes)
+	k.Scopes = slices.Compact(k.Scopes)
+
+	now := time.Now().UTC()
+	if k.ExpiresAt == nil {
+		expiresAt := now.Add(DefaultAPIKeyLifetime)
+		k.ExpiresAt = &expiresAt
+	}
+	if !k.ExpiresAt.After(now) {
+		return "", ErrInvalidAPIKeyExpiry
+	}
+
+	prefix, err := utils.GenerateSecureToken(6)
+	if err != nil {
+		utils.Logger.Error("Failed to generate API key prefix", "user_id", k.UserID, "error", err)
+		return "", err
+	}
+	secret, err := utils.GenerateSecureToken(32)
+	if err != nil {
+		utils.Logger.Error("Failed to generate API key", "user_id", k.UserID, "error", err)
+		return "", err
+	}
+	k.Prefix = APIKeyPrefix + prefix
+	key := k.Prefix + "_" + secret
+
+	query := `
+    INSERT INTO api_keys (user_id, name, prefix, key_hash, scopes, expires_at)
+    VALUES ($1, $2, $3, $4, $5, $6)
+    RETURNING id, created_at
+    `
+	err = db.DB.QueryRow(query, k.UserID, k.Name, k.Prefix, utils.HashToken(key), pq.Array(k.Scopes),
+		k.ExpiresAt.UTC()).Scan(&k.ID, &k.CreatedAt)
+	if err != nil {
+		utils.Logger.Error("Failed to save API key to database", "user_id", k.UserID, "error", err)
+		return "", err
+	}
+	utils.Logger.Debug("API key saved to database", "api_key_id", k.ID, "user_id", k.UserID)
+	return key, nil
+}
+
+// Delete revokes the key.
+func (k *APIKey) Delete() error {
+	_, err := db.DB.Exec("DELETE FROM api_keys WHERE id = $1", k.ID)
+	if err != nil {
+		utils.Logger.Error("Failed to delete API key from database", "api_key_id", k.ID, "error", err)
+		return err
+	}
+	utils.Logger.Debug("API key deleted from database", "api_key_id", k.ID)
+	return nil
+}
+
+func GetAPIKeysForUser(userID int64) ([]APIKey, error) {
+	query := "SELECT " + apiKeyColumns + " FROM api_keys WHERE user_id = $1 ORDER BY created_at DESC, id DESC"
+	rows, err := db.DB.Query(query, userID)
+	if err != nil {
+		utils.Logger.Error("Failed to query API keys", "user_id", userID, "error", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	keys := []APIKey{}
+	for rows.Next() {
+		k, err := scanAPIKey(rows)
+		if err != nil {
+			utils.Logger.Error("Failed to scan API key row", "user_id", userID, "error", err)
+			return nil, err
+		}
+		keys = append(keys, *k)
+	}
+
+	utils.Logger.Debug("Retrieved API keys", "user_id", userID, "count", len(keys))
+	return keys, nil
+}
+
+// GetAPIKey loads one of the user's keys. It returns ErrUnknownAPIKey if the
+// user has no such key.
+func GetAPIKey(userID, id int64) (*APIKey, error) {
+	query := "SELECT " + apiKeyColumns + " FROM api_keys WHERE id = $1 AND user_id = $2"
+	k, err := scanAPIKey(db.DB.QueryRow(query, id, userID))
+	if err == sql.ErrNoRows {
+		return nil, ErrUnknownAPIKey
+	}
+	if err != nil {
+		utils.Logger.Error("Failed to get API key", "api_key_id", id, "error", err)
+		return nil, err
+	}
+	return k, nil
+}
+
+// AuthenticateAPIKey looks up who a key belongs to and records that it was
+// used. Expired keys and keys of deleted users are rejected with
+// ErrInvalidAPIKey.
+func AuthenticateAPIKey(key string) (*APIKeyIdentity, error) {
+	query := `
+        SELECT k.id, k.user_id, u.role, k.scopes
+        FROM api_keys k
+        JOIN users u ON u.id = k.user_id
+        WHERE k.key_hash = $1 AND k.expires_at > $2 AND u.deleted_at IS NULL
+    `
+	var identity APIKeyIdentity
+	err := db.DB.QueryRow(query, utils.HashToken(key), time.Now().UTC()).Scan(&identity.KeyID, &identity.UserID,
+		&identity.Role, pq.Array(&identity.Scopes))
+	if err == sql.ErrNoRows {
+		return nil, ErrInvalidAPIKey
+	}
+	if err != nil {
+		utils.Logger.Error("Failed to look up API key", "error", err)
+		return nil, err
+	}
+
+	// Busy integrations would otherwise write on every request
+	query = `
+    UPDATE api_keys SET last_used_at = NOW()
+    WHERE id = $1 AND (last_used_at IS NULL OR last_used_at < NOW() - INTERVAL '1 minute')
+    `
+	_, err = db.DB.Exec(query, identity.KeyID)
+	if err != nil {
+		// Not worth failing the request over
+		utils.Logger.Error("Failed to record API key use", "api_key_id", identity.KeyID, "error", err)
+	}
+	return &identity, nil
+}
@@ -0,0 +1,178 @@
+package models
+
+import (
+	"database/sql"
+	"errors"
+	"time"
+
+	"example.com/event-booking-api/db"
+	"example.com/event-booking-api/utils"
+)
+
+var ErrRegistrationNotPending = errors.New("registration is not awaiting review")
+
+// Application is a user's registration for an event that requires approval,
+// as shown to the applicant.
+type Application struct {
+	RegistrationID int64      `json:"registration_id"`
+	EventID        int64      `json:"event_id"`
+	EventTitle     string     `json:"event_title"`
+	EventDate      time.Time  `json:"event_date"`
+	OccurrenceDate *time.Time `json:"occurrence_date,omitempty"`
+	Status         string     `json:"status"`
+	AppliedAt      time.Time  `json:"applied_at"`
+	ReviewedAt     *time.Time `json:"reviewed_at,omitempty"`
+}
+
+// ApproveRegistration accepts a pending application. The applicant gets a
+// seat if one is free and joins the waitlist otherwise, exactly as if they
+// had registered for an event without approval at that moment. Seated
+// events cannot require approval; applications made to one before that rule
+// booked their seats when applying and are always confirmed.
+func (e *Event) ApproveRegistration(registrationID, reviewerID int64) (*Registration, error) {
+	return e.reviewRegistration(registrationID, reviewerID, true)
+}
+
+// RejectRegistration turns down a pending application and frees any seats
+// it booked.
+func (e *Event) RejectRegistration(registrationID, reviewerID int64) (*Registration, error) {
+	return e.reviewRegistration(registrationID, reviewerID, false)
+}
+
+func (e *Event) reviewRegistration(registrationID, reviewerID int64, approve bool) (*Registration, error) {
+	tx, err := db.DB.Begin()
+	if err != nil {
+		utils.Logger.Error("Failed to begin review transaction", "registration_id", registrationID, "error", err)
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	locked, err := lockEvent(tx, e.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	query := `
+        SELECT r.id, r.user_id, r.event_id, r.occurrence_date, r.ticket_type_id, r.party_size,
+            r.status, r.registered_at, t.quantity
+        FROM registrations r
+        LEFT JOIN ticket_types t ON t.id = r.ticket_type_id
+        WHERE r.id = $1 AND r.event_id = $2
+        FOR UPDATE OF r
+    `
+	var r Registration
+	var occurrence sql.NullTime
+	var ticketTypeID sql.NullInt64
+	var quantity sql.NullInt64
+	err = tx.QueryRow(query, registrationID, e.ID).Scan(&r.ID, &r.UserID, &r.EventID, &occurrence, &ticketTypeID,
+		&r.PartySize, &r.Status, &r.RegisteredAt, &quantity)
+	if err == sql.ErrNoRows {
+		return nil, ErrRegistrationMissing
+	}
+	if err != nil {
+		utils.Logger.Error("Failed to look up registration for review", "registration_id", registrationID, "error", err)
+		return nil, err
+	}
+	if occurrence.Valid {
+		r.OccurrenceDate = &occurrence.Time
+	}
+	if ticketTypeID.Valid {
+		r.TicketTypeID = &ticketTypeID.Int64
+	}
+	if r.Status != RegistrationPending {
+		return nil, ErrRegistrationNotPending
+	}
+
+	r.Status = RegistrationRejected
+	if approve {
+		r.Status = RegistrationConfirmed
+		if !e.IsSeated() {
+			available, err := fitsSeats(tx, e.ID, locked.capacity, quantity, r.OccurrenceDate, r.TicketTypeID, r.PartySize)
+			if err != nil {
+				return nil, err
+			}
+			if !available {
+				r.Status = RegistrationWaitlisted
+			}
+		}
+	} else {
+		_, err = tx.Exec("DELETE FROM event_seats WHERE registration_id = $1", r.ID)
+		if err != nil {
+			utils.Logger.Error("Failed to release seats of rejected registration", "registration_id", r.ID, "error", err)
+			return nil, err
+		}
+	}
+
+	query = "UPDATE registrations SET status = $1, reviewed_at = NOW(), reviewed_by = $2 WHERE id = $3"
+	_, err = tx.Exec(query, r.Status, reviewerID, r.ID)
+	if err != nil {
+		utils.Logger.Error("Failed to update reviewed registration",
+			"registration_id", r.ID,
+			"status", r.Status,
+			"error", err)
+		return nil, err
+	}
+
+	if r.Status == RegistrationWaitlisted {
+		position, err := waitlistPosition(tx, e.ID, r.OccurrenceDate, r.ID)
+		if err != nil {
+			return nil, err
+		}
+		r.WaitlistPosition = &position
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		utils.Logger.Error("Failed to commit registration review", "registration_id", r.ID, "error", err)
+		return nil, err
+	}
+
+	utils.Logger.Debug("Registration reviewed",
+		"event_id", e.ID,
+		"registration_id", r.ID,
+		"reviewer_id", reviewerID,
+		"status", r.Status)
+	return &r, nil
+}
+
+// GetApplicationsForUser lists the user's registrations for events that
+// require approval, newest first. Applications left pending or rejected
+// after the organizer turned approval off are included too.
+func GetApplicationsForUser(userID int64) ([]Application, error) {
+	query := `
+        SELECT r.id, e.id, e.title, e.date, r.occurrence_date, r.status, r.registered_at, r.reviewed_at
+        FROM registrations r
+        JOIN events e ON e.id = r.event_id
+        WHERE r.user_id = $1 AND e.deleted_at IS NULL
+          AND (e.requires_approval OR r.status IN ($2, $3))
+        ORDER BY r.registered_at DESC, r.id DESC
+    `
+	rows, err := db.DB.Query(query, userID, RegistrationPending, RegistrationRejected)
+	if err != nil {
+		utils.Logger.Error("Failed to query applications", "user_id", userID, "error", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	applications := []Application{}
+	for rows.Next() {
+		var a Application
+		var occurrence, reviewedAt sql.NullTime
+		err := rows.Scan(&a.RegistrationID, &a.EventID, &a.EventTitle, &a.EventDate, &occurrence,
+			&a.Status, &a.AppliedAt, &reviewedAt)
+		if err != nil {
+			utils.Logger.Error("Failed to scan application row", "error", err)
+			return nil, err
+		}
+		if occurrence.Valid {
+			a.OccurrenceDate = &occurrence.Time
+		}
+		if reviewedAt.Valid {
+			a.ReviewedAt = &reviewedAt.Time
+		}
+		applications = append(applications, a)
+	}
+
+	utils.Logger.Debug("Retrieved applications", "user_id", userID, "count", len(applications))
+	return applications, nil
+}
@@ -0,0 +1,118 @@
+package models
+
+import (
+	"errors"
+
+	"example.com/event-booking-api/db"
+	"example.com/event-booking-api/utils"
+	"github.com/lib/pq"
+)
+
+var (
+	ErrDuplicateCategory = errors.New("a category with this name already exists")
+	ErrUnknownCategory   = errors.New("category does not exist")
+)
+
+type Category struct {
+	ID          int64  `json:"id"`
+	Name        string `json:"name" binding:"required,max=100"`
+	Description string `json:"description" binding:"max=500"`
+}
+
+func (c *Category) Save() error {
+	query := `INSERT INTO categories (name, description) VALUES ($1, $2) RETURNING id`
+	err := db.DB.QueryRow(query, c.Name, c.Description).Scan(&c.ID)
+	if isUniqueViolation(err) {
+		return ErrDuplicateCategory
+	}
+	if err != nil {
+		utils.Logger.Error("Failed to save category to database", "name", c.Name, "error", err)
+		return err
+	}
+	utils.Logger.Debug("Category saved to database", "category_id", c.ID, "name", c.Name)
+	return nil
+}
+
+func (c *Category) Update() error {
+	query := `UPDATE categories SET name = $1, description = $2 WHERE id = $3`
+	_, err := db.DB.Exec(query, c.Name, c.Description, c.ID)
+	if isUniqueViolation(err) {
+		return ErrDuplicateCategory
+	}
+	if err != nil {
+		utils.Logger.Error("Failed to update category in database", "category_id", c.ID, "error", err)
+		return err
+	}
+	utils.Logger.Debug("Category updated in database", "category_id", c.ID, "name", c.Name)
+	return nil
+}
+
+// Delete removes the category. Events in it become uncategorised.
+func (c *Category) Delete() error {
+	query := `DELETE FROM categories WHERE id = $1`
+	_, err := db.DB.Exec(query, c.ID)
+	if err != nil {
+		utils.Logger.Error("Failed to delete category from database", "category_id", c.ID, "error", err)
+		return err
+	}
+	utils.Logger.Debug("Category deleted from database", "category_id", c.ID)
+	return nil
+}
+
+func GetAllCategories() ([]Category, error) {
+	query := `SELECT id, name, description FROM categories ORDER BY name`
+	rows, err := db.DB.Query(query)
+	if err != nil {
+		utils.Logger.Error("Failed to query all categories", "error", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	categories := []Category{}
+	for rows.Next() {
+		var c Category
+		err := rows.Scan(&c.ID, &c.Name, &c.Description)
+		if err != nil {
+			utils.Logger.Error("Failed to scan category row", "error", err)
+			return nil, err
+		}
+		categories = append(categories, c)
+	}
+
+	utils.Logger.Debug("Retrieved all categories from database", "count", len(categories))
+	return categories, nil
+}
+
+func GetCategoryByID(id int64) (*Category, error) {
+	query := `SELECT id, name, description FROM categories WHERE id = $1`
+	row := db.DB.QueryRow(query, id)
+
+	var c Category
+	err := row.Scan(&c.ID, &c.Name, &c.Description)
+	if err != nil {
+		utils.Logger.Error("Failed to get category by ID", "category_id", id, "error", err)
+		return nil, err
+	}
+
+	utils.Logger.Debug("Retrieved category by ID", "category_id", id, "name", c.Name)
+	return &c, nil
+}
+
+func isUniqueViolation(err error) bool {
+	var pqErr *pq.Error
+	return errors.As(err, &pqErr) && pqErr.Code == "23505"
+}
+
+// violatedConstraint names the constraint a database error is about, if any.
+func violatedConstraint(err error) string {
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) {
+		return pqErr.Constraint
+	}
+	return ""
+}
+
+func isForeignKeyViolation(err error) bool {
+	var pqErr *pq.Error
+	return errors.As(err, &pqErr) && pqErr.Code == "23503"
+}
@@ -0,0 +1,120 @@
+package models
+
+import (
+	"database/sql"
+	"errors"
+	"time"
+
+	"example.com/event-booking-api/db"
+	"example.com/event-booking-api/utils"
+)
+
+var (
+	ErrInvalidTicket      = errors.New("ticket does not belong to this event")
+	ErrTicketNotConfirmed = errors.New("registration is not confirmed")
+	ErrAlreadyCheckedIn   = errors.New("attendee is already checked in")
+	ErrOccurrenceRequired = errors.New("an occurrence date is required")
+)
+
+type CheckIn struct {
+	ID             int64      `json:"id"`
+	RegistrationID int64      `json:"registration_id"`
+	UserID         int64      `json:"user_id"`
+	OccurrenceDate *time.Time `json:"occurrence_date,omitempty"`
+	CheckedInAt    time.Time  `json:"checked_in_at"`
+}
+
+// CheckIn records that the holder of the registration arrived at the event.
+// Series registrations of recurring events are checked in per occurrence, so
+// occurrenceDate is required for them; other registrations ignore it unless
+// it contradicts the registration. A second scan returns ErrAlreadyCheckedIn
+// together with the earlier check-in.
+func (e *Event) CheckIn(registrationID int64, occurrenceDate *time.Time, staffID int64) (*CheckIn, error) {
+	tx, err := db.DB.Begin()
+	if err != nil {
+		utils.Logger.Error("Failed to begin check-in transaction", "registration_id", registrationID, "error", err)
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	// Locking the registration serialises concurrent scans of the same ticket
+	query := `
+        SELECT event_id, user_id, occurrence_date, status FROM registrations
+        WHERE id = $1
+        FOR UPDATE
+    `
+	var eventID int64
+	var registeredOccurrence sql.NullTime
+	var status string
+	c := &CheckIn{RegistrationID: registrationID}
+	err = tx.QueryRow(query, registrationID).Scan(&eventID, &c.UserID, &registeredOccurrence, &status)
+	if err == sql.ErrNoRows {
+		return nil, ErrRegistrationMissing
+	}
+	if err != nil {
+		utils.Logger.Error("Failed to look up registration for check-in", "registration_id", registrationID, "error", err)
+		return nil, err
+	}
+	if eventID != e.ID {
+		return nil, ErrInvalidTicket
+	}
+	if status != RegistrationConfirmed {
+		return nil, ErrTicketNotConfirmed
+	}
+
+	switch {
+	case registeredOccurrence.Valid:
+		occurrence := registeredOccurrence.Time.UTC()
+		if occurrenceDate != nil && !occurrenceDate.Equal(occurrence) {
+			return nil, ErrInvalidOccurrence
+		}
+		c.OccurrenceDate = &occurrence
+	case e.IsRecurring():
+		if occurrenceDate == nil {
+			return nil, ErrOccurrenceRequired
+		}
+		c.OccurrenceDate, err = e.resolveOccurrence(occurrenceDate)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	query = `
+        SELECT id, checked_in_at FROM check_ins
+        WHERE registration_id = $1 AND occurrence_date IS NOT DISTINCT FROM $2
+    `
+	err = tx.QueryRow(query, registrationID, c.OccurrenceDate).Scan(&c.ID, &c.CheckedInAt)
+	if err == nil {
+		return c, ErrAlreadyCheckedIn
+	}
+	if err != sql.ErrNoRows {
+		utils.Logger.Error("Failed to look up existing check-in", "registration_id", registrationID, "error", err)
+		return nil, err
+	}
+
+	query = `
+        INSERT INTO check_ins (registration_id, occurrence_date, checked_in_by)
+        VALUES ($1, $2, $3)
+        RETURNING id, checked_in_at
+    `
+	err = tx.QueryRow(query, registrationID, c.OccurrenceDate, staffID).Scan(&c.ID, &c.CheckedInAt)
+	if err != nil {
+		utils.Logger.Error("Failed to save check-in",
+			"registration_id", registrationID,
+			"event_id", e.ID,
+			"error", err)
+		return nil, err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		utils.Logger.Error("Failed to commit check-in", "registration_id", registrationID, "error", err)
+		return nil, err
+	}
+
+	utils.Logger.Debug("Attendee checked in",
+		"registration_id", registrationID,
+		"event_id", e.ID,
+		"user_id", c.UserID)
+	return c, nil
+}
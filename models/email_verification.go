@@ -0,0 +1,107 @@
+package models
+
+import (
+	"database/sql"
+	"errors"
+	"time"
+
+	"example.com/event-booking-api/db"
+	"example.com/event-booking-api/utils"
+)
+
+var (
+	ErrInvalidVerificationToken = errors.New("email verification token is invalid or expired")
+	ErrEmailAlreadyVerified     = errors.New("email is already verified")
+	ErrVerificationThrottled    = errors.New("a verification email was sent recently")
+)
+
+var (
+	// EmailVerificationDuration is how long a verification link works.
+	EmailVerificationDuration = 24 * time.Hour
+
+	// VerificationResendInterval is the least time between two verification
+	// emails to the same user.
+	VerificationResendInterval = 5 * time.Minute
+)
+
+// ClaimVerificationEmail records that a verification email is about to be
+// sent to the user. It returns ErrVerificationThrottled if one went out less
+// than VerificationResendInterval ago, so concurrent resends send one email.
+func (u *User) ClaimVerificationEmail() error {
+	query := `
+    UPDATE users SET verification_sent_at = NOW()
+    WHERE id = $1 AND deleted_at IS NULL AND email_verified_at IS NULL
+      AND (verification_sent_at IS NULL OR verification_sent_at < NOW() - make_interval(secs => $2))
+    `
+	res, err := db.DB.Exec(query, u.ID, VerificationResendInterval.Seconds())
+	if err != nil {
+		utils.Logger.Error("Failed to claim verification email", "user_id", u.ID, "error", err)
+		return err
+	}
+	claimed, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if claimed == 1 {
+		return nil
+	}
+
+	verified, err := IsEmailVerified(u.ID)
+	if err != nil {
+		return err
+	}
+	if verified {
+		return ErrEmailAlreadyVerified
+	}
+	return ErrVerificationThrottled
+}
+
+// NewVerificationToken signs a verification token for the user's current
+// email address.
+func (u *User) NewVerificationToken() (string, error) {
+	token, err := utils.GenerateEmailVerificationToken(u.ID, u.Email, EmailVerificationDuration)
+	if err != nil {
+		utils.Logger.Error("Failed to generate verification token", "user_id", u.ID, "error", err)
+		return "", err
+	}
+	return token, nil
+}
+
+// VerifyEmail marks the address a verification token was sent to as
+// verified. Verifying twice is harmless.
+func VerifyEmail(token string) (*User, error) {
+	userID, email, err := utils.VerifyEmailVerificationToken(token)
+	if err != nil {
+		utils.Logger.Warn("Invalid email verification token", "error", err)
+		return nil, ErrInvalidVerificationToken
+	}
+
+	query := `
+    UPDATE users SET email_verified_at = COALESCE(email_verified_at, NOW())
+    WHERE id = $1 AND email = $2 AND deleted_at IS NULL
+    RETURNING ` + userColumns
+	u, err := scanUser(db.DB.QueryRow(query, userID, email))
+	if err == sql.ErrNoRows {
+		return nil, ErrInvalidVerificationToken
+	}
+	if err != nil {
+		utils.Logger.Error("Failed to verify email", "user_id", userID, "error", err)
+		return nil, err
+	}
+	utils.Logger.Debug("Email verified", "user_id", u.ID)
+	return u, nil
+}
+
+func IsEmailVerified(userID int64) (bool, error) {
+	query := "SELECT email_verified_at IS NOT NULL FROM users WHERE id = $1 AND deleted_at IS NULL"
+	var verified bool
+	err := db.DB.QueryRow(query, userID).Scan(&verified)
+	if err == sql.ErrNoRows {
+		return false, nil
+	}
+	if err != nil {
+		utils.Logger.Error("Failed to check email verification", "user_id", userID, "error", err)
+		return false, err
+	}
+	return verified, nil
+}
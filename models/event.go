@@ -1,12 +1,29 @@
 package models
 
 import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"strings"
 	"time"
 
 	"example.com/event-booking-api/db"
 	"example.com/event-booking-api/utils"
+	"github.com/lib/pq"
 )
 
+var (
+	ErrAlreadyRegistered = errors.New("user is already registered for this event")
+	ErrNotRegistered     = errors.New("user is not registered for this event")
+	// ErrVersionConflict is returned when a row changed since the caller
+	// read it.
+	ErrVersionConflict = errors.New("record was modified concurrently")
+)
+
+// eventColumns lists the columns scanned by scanEvent, in order.
+const eventColumns = "id, title, description, location, date, user_id, capacity, recurrence_rule, recurrence_exdates, category_id, status, recurrence_ends_at, version, " +
+	"registration_opens_at, registration_closes_at, unregistration_closes_at, requires_approval, venue_id"
+
 type Event struct {
 	ID          int64     `json:"id"`
 	Title       string    `json:"title" binding:"required"`
@@ -14,15 +31,159 @@ type Event struct {
 	Location    string    `json:"location" binding:"required"`
 	Date        time.Time `json:"date" binding:"required"`
 	UserID      int64     `json:"user_id"`
+	Status      string    `json:"status" binding:"omitempty,oneof=draft published"`
+	Capacity    *int      `json:"capacity" binding:"omitempty,gt=0"`
+	CategoryID  *int64    `json:"category_id" binding:"omitempty,gt=0"`
+	Tags        []string  `json:"tags" binding:"omitempty,max=20,dive,max=50"`
+
+	// Registration windows. Registration and unregistration close when the
+	// event starts unless set otherwise.
+	RegistrationOpensAt    *time.Time `json:"registration_opens_at"`
+	RegistrationClosesAt   *time.Time `json:"registration_closes_at"`
+	UnregistrationClosesAt *time.Time `json:"unregistration_closes_at"`
+
+	// RequiresApproval makes registrations pending until the organizer
+	// approves them. Seated events cannot require approval.
+	RequiresApproval bool `json:"requires_approval"`
+
+	// VenueID gives the event reserved seating in the venue's layout.
+	VenueID *int64 `json:"venue_id" binding:"omitempty,gt=0"`
+
+	// Version is bumped on every update. It is exposed as the ETag, not in
+	// the body.
+	Version int `json:"-"`
+
+	// RecurrenceRule is an RFC 5545 RRULE value, e.g. "FREQ=WEEKLY;BYDAY=TU".
+	// The series starts at Date.
+	RecurrenceRule    *string      `json:"recurrence_rule"`
+	RecurrenceExDates []time.Time  `json:"recurrence_exdates,omitempty"`
+	Occurrences       []Occurrence `json:"occurrences,omitempty"`
+}
+
+// RegistrationOptions carries the optional parts of a registration request.
+type RegistrationOptions struct {
+	// OccurrenceDate picks a single occurrence of a recurring event. When
+	// nil the user registers for the whole series.
+	OccurrenceDate *time.Time `json:"occurrence_date"`
+	// TicketTypeID is required when the event sells ticket types.
+	TicketTypeID *int64 `json:"ticket_type_id" binding:"omitempty,gt=0"`
+	// Guests come along with the registrant and take a seat each.
+	Guests []Guest `json:"guests" binding:"omitempty,max=10,dive"`
+	// Answers to the event's registration questions, keyed by question ID.
+	Answers map[int64]json.RawMessage `json:"answers"`
+	// PromoCode is an optional discount code for the event.
+	PromoCode *string `json:"promo_code" binding:"omitempty,max=50"`
+}
+
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+// scanEvent reads a row selected with eventColumns. Any extra destinations
+// are filled from the columns that follow them.
+func scanEvent(row rowScanner, extra ...any) (*Event, error) {
+	var e Event
+	var capacity sql.NullInt64
+	var rule sql.NullString
+	var exdates pq.StringArray
+	var categoryID sql.NullInt64
+	var recurrenceEndsAt sql.NullTime
+	var opensAt, closesAt, unregistrationClosesAt sql.NullTime
+	var venueID sql.NullInt64
+	dest := []any{&e.ID, &e.Title, &e.Description, &e.Location, &e.Date, &e.UserID,
+		&capacity, &rule, &exdates, &categoryID, &e.Status, &recurrenceEndsAt, &e.Version,
+		&opensAt, &closesAt, &unregistrationClosesAt, &e.RequiresApproval, &venueID}
+	err := row.Scan(append(dest, extra...)...)
+	if err != nil {
+		return nil, err
+	}
+	if capacity.Valid {
+		c := int(capacity.Int64)
+		e.Capacity = &c
+	}
+	if rule.Valid {
+		e.RecurrenceRule = &rule.String
+	}
+	if categoryID.Valid {
+		e.CategoryID = &categoryID.Int64
+	}
+	if opensAt.Valid {
+		e.RegistrationOpensAt = &opensAt.Time
+	}
+	if closesAt.Valid {
+		e.RegistrationClosesAt = &closesAt.Time
+	}
+	if unregistrationClosesAt.Valid {
+		e.UnregistrationClosesAt = &unregistrationClosesAt.Time
+	}
+	if venueID.Valid {
+		e.VenueID = &venueID.Int64
+	}
+	e.RecurrenceExDates, err = parseExDates(exdates)
+	if err != nil {
+		return nil, err
+	}
+	if recurrenceEndsAt.Valid {
+		e.applyDerivedStatus(&recurrenceEndsAt.Time)
+	} else {
+		e.applyDerivedStatus(nil)
+	}
+	return &e, nil
+}
+
+// prepareRecurrence validates the recurrence rule and returns the start of
+// the last occurrence, if the series ends.
+func (e *Event) prepareRecurrence() (*time.Time, error) {
+	if e.RecurrenceRule != nil && strings.TrimSpace(*e.RecurrenceRule) == "" {
+		e.RecurrenceRule = nil
+	}
+	if !e.IsRecurring() {
+		e.RecurrenceExDates = nil
+		return nil, nil
+	}
+	return e.recurrenceEnd()
 }
 
 func (e *Event) Save() error {
+	err := e.validateRegistrationWindow()
+	if err != nil {
+		return err
+	}
+	err = e.validateSeating()
+	if err != nil {
+		return err
+	}
+	recurrenceEnd, err := e.prepareRecurrence()
+	if err != nil {
+		return err
+	}
+
+	e.Tags = NormalizeTags(e.Tags)
+	if e.Status == "" {
+		e.Status = EventDraft
+	}
+
+	tx, err := db.DB.Begin()
+	if err != nil {
+		utils.Logger.Error("Failed to begin event save transaction", "title", e.Title, "error", err)
+		return err
+	}
+	defer tx.Rollback()
+
 	query := `
-    INSERT INTO events (title, description, location, date, user_id)
-    VALUES ($1, $2, $3, $4, $5)
-    RETURNING id
+    INSERT INTO events (title, description, location, date, user_id, capacity,
+        recurrence_rule, recurrence_exdates, recurrence_ends_at, category_id, status,
+        registration_opens_at, registration_closes_at, unregistration_closes_at, requires_approval, venue_id)
+    VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16)
+    RETURNING id, version
     `
-	err := db.DB.QueryRow(query, e.Title, e.Description, e.Location, e.Date, e.UserID).Scan(&e.ID)
+	err = tx.QueryRow(query, e.Title, e.Description, e.Location, e.Date, e.UserID, e.Capacity,
+		e.RecurrenceRule, pq.Array(formatExDates(e.RecurrenceExDates)), recurrenceEnd, e.CategoryID,
+		e.Status, e.RegistrationOpensAt, e.RegistrationClosesAt, e.UnregistrationClosesAt,
+		e.RequiresApproval, e.VenueID).Scan(&e.ID, &e.Version)
+	if isForeignKeyViolation(err) {
+		return eventReferenceError(err)
+	}
 	if err != nil {
 		utils.Logger.Error("Failed to save event to database",
 			"title", e.Title,
@@ -30,17 +191,78 @@ func (e *Event) Save() error {
 			"error", err)
 		return err
 	}
+
+	err = replaceEventTags(tx, e.ID, e.Tags)
+	if err != nil {
+		return err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		utils.Logger.Error("Failed to commit event save", "event_id", e.ID, "error", err)
+		return err
+	}
 	utils.Logger.Debug("Event saved to database", "event_id", e.ID, "title", e.Title)
 	return nil
 }
 
+// eventReferenceError tells apart the foreign keys an event write can break.
+func eventReferenceError(err error) error {
+	if violatedConstraint(err) == "fk_event_venue" {
+		return ErrUnknownVenue
+	}
+	return ErrUnknownCategory
+}
+
+// Update overwrites the event, provided it is still at e.Version. On success
+// e.Version holds the new version; ErrVersionConflict means someone else
+// updated the event first.
 func (e *Event) Update() error {
+	err := e.validateRegistrationWindow()
+	if err != nil {
+		return err
+	}
+	err = e.validateSeating()
+	if err != nil {
+		return err
+	}
+	recurrenceEnd, err := e.prepareRecurrence()
+	if err != nil {
+		return err
+	}
+	e.Tags = NormalizeTags(e.Tags)
+
+	tx, err := db.DB.Begin()
+	if err != nil {
+		utils.Logger.Error("Failed to begin event update transaction", "event_id", e.ID, "error", err)
+		return err
+	}
+	defer tx.Rollback()
+
+	err = checkVenueChange(tx, e.ID, e.VenueID)
+	if err != nil {
+		return err
+	}
+
 	query := `
     UPDATE events
-    SET title = $1, description = $2, location = $3, date = $4
-    WHERE id = $5
+    SET title = $1, description = $2, location = $3, date = $4, capacity = $5,
+        recurrence_rule = $6, recurrence_exdates = $7, recurrence_ends_at = $8, category_id = $9,
+        registration_opens_at = $10, registration_closes_at = $11, unregistration_closes_at = $12,
+        requires_approval = $13, venue_id = $14, version = version + 1, updated_at = NOW()
+    WHERE id = $15 AND version = $16 AND deleted_at IS NULL
+    RETURNING version
     `
-	_, err := db.DB.Exec(query, e.Title, e.Description, e.Location, e.Date, e.ID)
+	err = tx.QueryRow(query, e.Title, e.Description, e.Location, e.Date, e.Capacity,
+		e.RecurrenceRule, pq.Array(formatExDates(e.RecurrenceExDates)), recurrenceEnd, e.CategoryID,
+		e.RegistrationOpensAt, e.RegistrationClosesAt, e.UnregistrationClosesAt, e.RequiresApproval,
+		e.VenueID, e.ID, e.Version).Scan(&e.Version)
+	if err == sql.ErrNoRows {
+		return ErrVersionConflict
+	}
+	if isForeignKeyViolation(err) {
+		return eventReferenceError(err)
+	}
 	if err != nil {
 		utils.Logger.Error("Failed to update event in database",
 			"event_id", e.ID,
@@ -48,12 +270,31 @@ func (e *Event) Update() error {
 			"error", err)
 		return err
 	}
+
+	err = replaceEventTags(tx, e.ID, e.Tags)
+	if err != nil {
+		return err
+	}
+
+	// A raised (or removed) capacity may free seats for people on the waitlist
+	_, err = promoteWaitlisted(tx, e.ID)
+	if err != nil {
+		return err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		utils.Logger.Error("Failed to commit event update", "event_id", e.ID, "error", err)
+		return err
+	}
 	utils.Logger.Debug("Event updated in database", "event_id", e.ID, "title", e.Title)
 	return nil
 }
 
+// Delete soft-deletes the event. It and its registrations are kept until
+// the purge job removes them, so an admin can still restore it.
 func (e *Event) Delete() error {
-	query := "DELETE FROM events WHERE id = $1"
+	query := "UPDATE events SET deleted_at = NOW() WHERE id = $1 AND deleted_at IS NULL"
 	_, err := db.DB.Exec(query, e.ID)
 	if err != nil {
 		utils.Logger.Error("Failed to delete event from database",
@@ -65,23 +306,199 @@ func (e *Event) Delete() error {
 	return nil
 }
 
-func (e *Event) Register(userID int64) error {
-	query := "INSERT INTO registrations (user_id, event_id) VALUES ($1, $2)"
-	_, err := db.DB.Exec(query, userID, e.ID)
+// Register signs the user up for the event, or for one occurrence of it. When
+// the event is at capacity the user is placed on the waitlist instead and the
+// returned registration carries their position in it. Events that require
+// approval take no seat yet: the registration stays pending until the
+// organizer reviews it. Only published events
+// that have not yet completed accept registrations, and only inside their
+// registration window.
+func (e *Event) Register(userID int64, opts RegistrationOptions) (*Registration, error) {
+	if e.Status == EventCompleted {
+		return nil, ErrRegistrationClosed
+	}
+	if e.Status != EventPublished {
+		return nil, ErrEventNotOpen
+	}
+
+	resolve := e.resolveOccurrence
+	if e.IsSeated() {
+		resolve = e.resolveSeatOccurrence
+	}
+	occurrence, err := resolve(opts.OccurrenceDate)
+	if err != nil {
+		return nil, err
+	}
+
+	err = e.checkRegistrationWindow(occurrence, time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	tx, err := db.DB.Begin()
+	if err != nil {
+		utils.Logger.Error("Failed to begin registration transaction", "event_id", e.ID, "error", err)
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	locked, err := lockEvent(tx, e.ID)
+	if err != nil {
+		return nil, err
+	}
+	if locked.status != EventPublished {
+		return nil, ErrEventNotOpen
+	}
+
+	ticketType, err := resolveTicketType(tx, e.ID, opts.TicketTypeID)
+	if err != nil {
+		return nil, err
+	}
+
+	if occurrence != nil {
+		// A series registration already covers every occurrence
+		covered, err := hasSeriesRegistration(tx, e.ID, userID)
+		if err != nil {
+			return nil, err
+		}
+		if covered {
+			return nil, ErrAlreadyRegistered
+		}
+	}
+
+	r := &Registration{
+		UserID:         userID,
+		EventID:        e.ID,
+		OccurrenceDate: occurrence,
+		Status:         RegistrationConfirmed,
+		PartySize:      1 + len(opts.Guests),
+		Guests:         opts.Guests,
+	}
+	quantity := sql.NullInt64{}
+	if ticketType != nil {
+		r.TicketTypeID = &ticketType.ID
+		if ticketType.Quantity != nil {
+			quantity = sql.NullInt64{Int64: int64(*ticketType.Quantity), Valid: true}
+		}
+	}
+
+	if locked.requiresApproval {
+		r.Status = RegistrationPending
+	} else {
+		available, err := fitsSeats(tx, e.ID, locked.capacity, quantity, occurrence, r.TicketTypeID, r.PartySize)
+		if err != nil {
+			return nil, err
+		}
+		if !available && e.IsSeated() {
+			// Seats are picked before registering, so there is no waitlist
+			return nil, ErrSoldOut
+		}
+		if !available {
+			r.Status = RegistrationWaitlisted
+		}
+	}
+
+	query := `
+    INSERT INTO registrations (user_id, event_id, occurrence_date, status, ticket_type_id, party_size)
+    VALUES ($1, $2, $3, $4, $5, $6)
+    RETURNING id, registered_at
+    `
+	err = tx.QueryRow(query, userID, e.ID, occurrence, r.Status, r.TicketTypeID, r.PartySize).Scan(&r.ID, &r.RegisteredAt)
+	if isUniqueViolation(err) {
+		return nil, ErrAlreadyRegistered
+	}
 	if err != nil {
 		utils.Logger.Error("Failed to register user for event",
 			"event_id", e.ID,
 			"user_id", userID,
 			"error", err)
-		return err
+		return nil, err
 	}
-	utils.Logger.Debug("User registered for event", "event_id", e.ID, "user_id", userID)
-	return nil
+
+	err = saveGuests(tx, r.ID, r.Guests)
+	if err != nil {
+		return nil, err
+	}
+
+	if e.IsSeated() {
+		err = bookHeldSeats(tx, r)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	err = saveAnswers(tx, e.ID, r.ID, opts.Answers)
+	if err != nil {
+		return nil, err
+	}
+
+	if opts.PromoCode != nil && strings.TrimSpace(*opts.PromoCode) != "" {
+		r.DiscountCents, err = redeemPromoCode(tx, *opts.PromoCode, r, ticketType)
+		if err != nil {
+			return nil, err
+		}
+		r.PromoCode = NormalizePromoCode(*opts.PromoCode)
+	}
+
+	if r.Status == RegistrationWaitlisted {
+		position, err := waitlistPosition(tx, e.ID, occurrence, r.ID)
+		if err != nil {
+			return nil, err
+		}
+		r.WaitlistPosition = &position
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		utils.Logger.Error("Failed to commit registration",
+			"event_id", e.ID,
+			"user_id", userID,
+			"error", err)
+		return nil, err
+	}
+
+	utils.Logger.Debug("User registered for event",
+		"event_id", e.ID,
+		"user_id", userID,
+		"status", r.Status)
+	return r, nil
 }
 
-func (e *Event) Unregister(userID int64) error {
-	query := "DELETE FROM registrations WHERE user_id = $1 AND event_id = $2"
-	_, err := db.DB.Exec(query, userID, e.ID)
+// Unregister removes the user's registration for the series, or for the given
+// occurrence. If that frees a seat, the longest-waiting users on the waitlist
+// are moved into it. Confirmed seats can only be given up until the
+// unregistration deadline; leaving the waitlist or withdrawing a pending
+// application is always allowed. Rejected applications stay on record so
+// they cannot simply be resubmitted.
+func (e *Event) Unregister(userID int64, occurrenceDate *time.Time) error {
+	var occurrence *time.Time
+	if occurrenceDate != nil {
+		date := occurrenceDate.UTC()
+		occurrence = &date
+	}
+
+	tx, err := db.DB.Begin()
+	if err != nil {
+		utils.Logger.Error("Failed to begin unregistration transaction", "event_id", e.ID, "error", err)
+		return err
+	}
+	defer tx.Rollback()
+
+	_, err = lockEvent(tx, e.ID)
+	if err != nil {
+		return err
+	}
+
+	query := `
+    DELETE FROM registrations
+    WHERE user_id = $1 AND event_id = $2 AND occurrence_date IS NOT DISTINCT FROM $3 AND status <> $4
+    RETURNING status
+    `
+	var status string
+	err = tx.QueryRow(query, userID, e.ID, occurrence, RegistrationRejected).Scan(&status)
+	if err == sql.ErrNoRows {
+		return ErrNotRegistered
+	}
 	if err != nil {
 		utils.Logger.Error("Failed to unregister user from event",
 			"event_id", e.ID,
@@ -89,45 +506,66 @@ func (e *Event) Unregister(userID int64) error {
 			"error", err)
 		return err
 	}
+
+	if status == RegistrationConfirmed {
+		closes := e.UnregistrationClosesAtFor(occurrence)
+		if closes != nil && !time.Now().Before(*closes) {
+			return ErrUnregistrationClosed
+		}
+
+		_, err = promoteWaitlisted(tx, e.ID)
+		if err != nil {
+			return err
+		}
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		utils.Logger.Error("Failed to commit unregistration",
+			"event_id", e.ID,
+			"user_id", userID,
+			"error", err)
+		return err
+	}
+
 	utils.Logger.Debug("User unregistered from event", "event_id", e.ID, "user_id", userID)
 	return nil
 }
 
-func GetAllEvents() ([]Event, error) {
-	query := "SELECT id, title, description, location, date, user_id FROM events"
-	rows, err := db.DB.Query(query)
+// resolveOccurrence checks that date, when given, is an occurrence of the
+// event and normalises it to UTC.
+func (e *Event) resolveOccurrence(date *time.Time) (*time.Time, error) {
+	if date == nil {
+		return nil, nil
+	}
+	ok, err := e.HasOccurrence(*date)
 	if err != nil {
-		utils.Logger.Error("Failed to query all events", "error", err)
 		return nil, err
 	}
-	defer rows.Close()
-
-	events := []Event{}
-	for rows.Next() {
-		var e Event
-		err := rows.Scan(&e.ID, &e.Title, &e.Description, &e.Location, &e.Date, &e.UserID)
-		if err != nil {
-			utils.Logger.Error("Failed to scan event row", "error", err)
-			return nil, err
-		}
-		events = append(events, e)
+	if !ok {
+		return nil, ErrInvalidOccurrence
 	}
-
-	utils.Logger.Debug("Retrieved all events from database", "count", len(events))
-	return events, nil
+	occurrence := date.UTC()
+	return &occurrence, nil
 }
 
 func GetEventByID(id int64) (*Event, error) {
-	query := "SELECT id, title, description, location, date, user_id FROM events WHERE id = $1"
+	query := "SELECT " + eventColumns + " FROM events WHERE id = $1 AND deleted_at IS NULL"
 	row := db.DB.QueryRow(query, id)
 
-	var e Event
-	err := row.Scan(&e.ID, &e.Title, &e.Description, &e.Location, &e.Date, &e.UserID)
+	e, err := scanEvent(row)
 	if err != nil {
 		utils.Logger.Error("Failed to get event by ID", "event_id", id, "error", err)
 		return nil, err
 	}
 
+	events := []Event{*e}
+	err = loadEventTags(events)
+	if err != nil {
+		return nil, err
+	}
+	e.Tags = events[0].Tags
+
 	utils.Logger.Debug("Retrieved event by ID", "event_id", id, "title", e.Title)
-	return &e, nil
+	return e, nil
 }
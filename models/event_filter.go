@@ -0,0 +1,191 @@
+package models
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"example.com/event-booking-api/db"
+	"example.com/event-booking-api/utils"
+	"github.com/lib/pq"
+)
+
+const (
+	DefaultEventPageSize = 20
+	MaxEventPageSize     = 100
+)
+
+// EventFilter describes a page of events. It is bound straight from the
+// query string of GET /events.
+type EventFilter struct {
+	Limit    int        `form:"limit" binding:"omitempty,min=1,max=100"`
+	Offset   int        `form:"offset" binding:"omitempty,min=0"`
+	From     *time.Time `form:"from"`
+	To       *time.Time `form:"to"`
+	Location string     `form:"location"`
+	UserID   int64      `form:"user_id" binding:"omitempty,gt=0"`
+	Sort     string     `form:"sort" binding:"omitempty,oneof=date created_at"`
+	Order    string     `form:"order" binding:"omitempty,oneof=asc desc"`
+	Status   string     `form:"status" binding:"omitempty,oneof=draft published cancelled"`
+
+	CategoryID int64 `form:"category_id" binding:"omitempty,gt=0"`
+	// Tags is a comma-separated list. TagMatch "any" (the default) matches
+	// events with at least one of them, "all" only events with every one.
+	Tags     []string `form:"tags" collection_format:"csv"`
+	TagMatch string   `form:"tag_match" binding:"omitempty,oneof=any all"`
+}
+
+type EventPage struct {
+	Events []Event `json:"events"`
+	Total  int     `json:"total"`
+	Limit  int     `json:"limit"`
+	Offset int     `json:"offset"`
+}
+
+// conditions returns the SQL conditions for the filter. Placeholders are
+// numbered after the args already present, so callers can prepend their own.
+func (f *EventFilter) conditions(args []any) ([]string, []any) {
+	conditions := []string{"deleted_at IS NULL"}
+
+	if f.From != nil {
+		// Recurring events match as long as the series has not ended
+		args = append(args, *f.From)
+		conditions = append(conditions, fmt.Sprintf(
+			"(date >= $%[1]d OR (recurrence_rule IS NOT NULL AND (recurrence_ends_at IS NULL OR recurrence_ends_at >= $%[1]d)))",
+			len(args)))
+	}
+	if f.To != nil {
+		args = append(args, *f.To)
+		conditions = append(conditions, fmt.Sprintf("date < $%d", len(args)))
+	}
+	if f.Location != "" {
+		args = append(args, "%"+escapeLike(f.Location)+"%")
+		conditions = append(conditions, fmt.Sprintf("location ILIKE $%d", len(args)))
+	}
+	if f.UserID != 0 {
+		args = append(args, f.UserID)
+		conditions = append(conditions, fmt.Sprintf("user_id = $%d", len(args)))
+	}
+	if f.Status != "" {
+		args = append(args, f.Status)
+		conditions = append(conditions, fmt.Sprintf("status = $%d", len(args)))
+	}
+	if f.CategoryID != 0 {
+		args = append(args, f.CategoryID)
+		conditions = append(conditions, fmt.Sprintf("category_id = $%d", len(args)))
+	}
+	if tags := NormalizeTags(f.Tags); len(tags) > 0 {
+		args = append(args, pq.Array(tags))
+		if f.TagMatch == "all" {
+			args = append(args, len(tags))
+			conditions = append(conditions, fmt.Sprintf(
+				"id IN (SELECT event_id FROM event_tags WHERE tag = ANY($%d) GROUP BY event_id HAVING COUNT(*) = $%d)",
+				len(args)-1, len(args)))
+		} else {
+			conditions = append(conditions, fmt.Sprintf(
+				"id IN (SELECT event_id FROM event_tags WHERE tag = ANY($%d))", len(args)))
+		}
+	}
+
+	return conditions, args
+}
+
+func (f *EventFilter) orderBy() string {
+	column := "date"
+	if f.Sort == "created_at" {
+		column = "created_at"
+	}
+	direction := "ASC"
+	if f.Order == "desc" {
+		direction = "DESC"
+	}
+	return fmt.Sprintf(" ORDER BY %s %s, id %s", column, direction, direction)
+}
+
+// occurrenceWindow is the range recurring events are expanded over: the
+// filter's date range, defaulting to DefaultOccurrenceWindow from now.
+func (f *EventFilter) occurrenceWindow() (time.Time, time.Time) {
+	from := time.Now().UTC()
+	if f.From != nil {
+		from = f.From.UTC()
+	}
+	to := from.Add(DefaultOccurrenceWindow)
+	if f.To != nil {
+		to = f.To.UTC()
+	}
+	return from, to
+}
+
+func (f *EventFilter) normalize() {
+	if f.Limit <= 0 {
+		f.Limit = DefaultEventPageSize
+	}
+	if f.Limit > MaxEventPageSize {
+		f.Limit = MaxEventPageSize
+	}
+	if f.Offset < 0 {
+		f.Offset = 0
+	}
+}
+
+func ListEvents(f EventFilter) (*EventPage, error) {
+	f.normalize()
+	conditions, args := f.conditions(nil)
+	where := whereClause(conditions)
+
+	page := &EventPage{Events: []Event{}, Limit: f.Limit, Offset: f.Offset}
+
+	countQuery := "SELECT COUNT(*) FROM events" + where
+	err := db.DB.QueryRow(countQuery, args...).Scan(&page.Total)
+	if err != nil {
+		utils.Logger.Error("Failed to count events", "error", err)
+		return nil, err
+	}
+
+	args = append(args, f.Limit, f.Offset)
+	query := "SELECT " + eventColumns + " FROM events" + where + f.orderBy() +
+		fmt.Sprintf(" LIMIT $%d OFFSET $%d", len(args)-1, len(args))
+	rows, err := db.DB.Query(query, args...)
+	if err != nil {
+		utils.Logger.Error("Failed to query events", "error", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		e, err := scanEvent(rows)
+		if err != nil {
+			utils.Logger.Error("Failed to scan event row", "error", err)
+			return nil, err
+		}
+		page.Events = append(page.Events, *e)
+	}
+
+	err = loadEventTags(page.Events)
+	if err != nil {
+		return nil, err
+	}
+
+	from, to := f.occurrenceWindow()
+	err = expandEventOccurrences(page.Events, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	utils.Logger.Debug("Retrieved events from database",
+		"count", len(page.Events),
+		"total", page.Total,
+		"offset", f.Offset)
+	return page, nil
+}
+
+func whereClause(conditions []string) string {
+	if len(conditions) == 0 {
+		return ""
+	}
+	return " WHERE " + strings.Join(conditions, " AND ")
+}
+
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(s)
+}
@@ -0,0 +1,161 @@
+package models
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+
+	"example.com/event-booking-api/db"
+	"example.com/event-booking-api/utils"
+	"github.com/lib/pq"
+)
+
+// eventPatchFields lists the event fields a merge patch may set. Status has
+// its own endpoint and ownership cannot change.
+var eventPatchFields = map[string]patchField{
+	"title":              {field: "Title"},
+	"description":        {field: "Description"},
+	"location":           {field: "Location"},
+	"date":               {field: "Date"},
+	"capacity":           {field: "Capacity", nullable: true},
+	"category_id":        {field: "CategoryID", nullable: true},
+	"tags":               {field: "Tags", nullable: true},
+	"recurrence_rule":    {field: "RecurrenceRule", nullable: true},
+	"recurrence_exdates": {field: "RecurrenceExDates", nullable: true},
+
+	"registration_opens_at":    {field: "RegistrationOpensAt", nullable: true},
+	"registration_closes_at":   {field: "RegistrationClosesAt", nullable: true},
+	"unregistration_closes_at": {field: "UnregistrationClosesAt", nullable: true},
+	"requires_approval":        {field: "RequiresApproval"},
+	"venue_id":                 {field: "VenueID", nullable: true},
+}
+
+// ApplyMergePatch applies an RFC 7396 merge patch to the event in memory.
+// Call UpdateFields with the changed fields to store it.
+func (e *Event) ApplyMergePatch(patch map[string]json.RawMessage) (*MergePatchResult, error) {
+	return applyMergePatch(e, eventPatchFields, patch)
+}
+
+// UpdateFields stores the given fields of the event, named as in JSON, and
+// leaves every other column alone. Like Update it requires the event to
+// still be at e.Version.
+func (e *Event) UpdateFields(fields []string) error {
+	columns := []string{}
+	values := []any{}
+	set := func(column string, value any) {
+		values = append(values, value)
+		columns = append(columns, fmt.Sprintf("%s = $%d", column, len(values)))
+	}
+
+	err := e.validateRegistrationWindow()
+	if err != nil {
+		return err
+	}
+	err = e.validateSeating()
+	if err != nil {
+		return err
+	}
+
+	// The end of the series depends on the start, the rule and the
+	// exception dates, so it is recomputed when any of them changes
+	if slices.Contains(fields, "date") || slices.Contains(fields, "recurrence_rule") ||
+		slices.Contains(fields, "recurrence_exdates") {
+		recurrenceEnd, err := e.prepareRecurrence()
+		if err != nil {
+			return err
+		}
+		set("recurrence_exdates", pq.Array(formatExDates(e.RecurrenceExDates)))
+		set("recurrence_ends_at", recurrenceEnd)
+	}
+
+	for _, field := range fields {
+		switch field {
+		case "title":
+			set("title", e.Title)
+		case "description":
+			set("description", e.Description)
+		case "location":
+			set("location", e.Location)
+		case "date":
+			set("date", e.Date)
+		case "capacity":
+			set("capacity", e.Capacity)
+		case "category_id":
+			set("category_id", e.CategoryID)
+		case "recurrence_rule":
+			set("recurrence_rule", e.RecurrenceRule)
+		case "registration_opens_at":
+			set("registration_opens_at", e.RegistrationOpensAt)
+		case "registration_closes_at":
+			set("registration_closes_at", e.RegistrationClosesAt)
+		case "unregistration_closes_at":
+			set("unregistration_closes_at", e.UnregistrationClosesAt)
+		case "requires_approval":
+			set("requires_approval", e.RequiresApproval)
+		case "venue_id":
+			set("venue_id", e.VenueID)
+		}
+	}
+
+	tx, err := db.DB.Begin()
+	if err != nil {
+		utils.Logger.Error("Failed to begin event patch transaction", "event_id", e.ID, "error", err)
+		return err
+	}
+	defer tx.Rollback()
+
+	if slices.Contains(fields, "venue_id") {
+		err = checkVenueChange(tx, e.ID, e.VenueID)
+		if err != nil {
+			return err
+		}
+	}
+
+	values = append(values, e.ID, e.Version)
+	query := fmt.Sprintf(`
+    UPDATE events
+    SET %s
+    WHERE id = $%d AND version = $%d AND deleted_at IS NULL
+    RETURNING version
+    `, strings.Join(append(columns, "version = version + 1", "updated_at = NOW()"), ", "),
+		len(values)-1, len(values))
+	err = tx.QueryRow(query, values...).Scan(&e.Version)
+	if err == sql.ErrNoRows {
+		return ErrVersionConflict
+	}
+	if isForeignKeyViolation(err) {
+		return eventReferenceError(err)
+	}
+	if err != nil {
+		utils.Logger.Error("Failed to patch event in database",
+			"event_id", e.ID,
+			"fields", fields,
+			"error", err)
+		return err
+	}
+
+	if slices.Contains(fields, "tags") {
+		e.Tags = NormalizeTags(e.Tags)
+		err = replaceEventTags(tx, e.ID, e.Tags)
+		if err != nil {
+			return err
+		}
+	}
+
+	if slices.Contains(fields, "capacity") {
+		_, err = promoteWaitlisted(tx, e.ID)
+		if err != nil {
+			return err
+		}
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		utils.Logger.Error("Failed to commit event patch", "event_id", e.ID, "error", err)
+		return err
+	}
+	utils.Logger.Debug("Event patched in database", "event_id", e.ID, "fields", fields)
+	return nil
+}
@@ -0,0 +1,92 @@
+package models
+
+import (
+	"fmt"
+
+	"example.com/event-booking-api/db"
+	"example.com/event-booking-api/utils"
+)
+
+// EventSearch is a full-text query combined with the regular listing
+// filters. Sort and Order are ignored; results are ranked by relevance.
+type EventSearch struct {
+	EventFilter
+	Query string `form:"q" binding:"required"`
+}
+
+type EventSearchResult struct {
+	Event
+	Rank    float64 `json:"rank"`
+	Snippet string  `json:"snippet"`
+}
+
+type EventSearchPage struct {
+	Results []EventSearchResult `json:"results"`
+	Total   int                 `json:"total"`
+	Limit   int                 `json:"limit"`
+	Offset  int                 `json:"offset"`
+}
+
+const searchHeadlineOptions = "StartSel=<mark>, StopSel=</mark>, MaxWords=35, MinWords=15, MaxFragments=2"
+
+func SearchEvents(s EventSearch) (*EventSearchPage, error) {
+	s.normalize()
+
+	// $1 is the search query; filter placeholders follow it
+	conditions, args := s.conditions([]any{s.Query})
+	conditions = append([]string{"search_vector @@ query"}, conditions...)
+	from := " FROM events, websearch_to_tsquery('english', $1) query" + whereClause(conditions)
+
+	page := &EventSearchPage{Results: []EventSearchResult{}, Limit: s.Limit, Offset: s.Offset}
+
+	err := db.DB.QueryRow("SELECT COUNT(*)"+from, args...).Scan(&page.Total)
+	if err != nil {
+		utils.Logger.Error("Failed to count event search results", "query", s.Query, "error", err)
+		return nil, err
+	}
+
+	// The snippet covers every searched field, so a match in the title or
+	// location is highlighted too
+	args = append(args, searchHeadlineOptions, s.Limit, s.Offset)
+	query := "SELECT " + eventColumns + `,
+        ts_rank(search_vector, query) AS rank,
+        ts_headline('english', concat_ws(' ', title, description, location), query, $` + fmt.Sprint(len(args)-2) + `)` +
+		from +
+		fmt.Sprintf(" ORDER BY rank DESC, id LIMIT $%d OFFSET $%d", len(args)-1, len(args))
+
+	rows, err := db.DB.Query(query, args...)
+	if err != nil {
+		utils.Logger.Error("Failed to search events", "query", s.Query, "error", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var result EventSearchResult
+		e, err := scanEvent(rows, &result.Rank, &result.Snippet)
+		if err != nil {
+			utils.Logger.Error("Failed to scan event search row", "error", err)
+			return nil, err
+		}
+		result.Event = *e
+		page.Results = append(page.Results, result)
+	}
+
+	events := make([]Event, len(page.Results))
+	for i, r := range page.Results {
+		events[i] = r.Event
+	}
+	err = loadEventTags(events)
+	if err != nil {
+		return nil, err
+	}
+	for i := range page.Results {
+		page.Results[i].Tags = events[i].Tags
+	}
+
+	utils.Logger.Debug("Searched events",
+		"query", s.Query,
+		"count", len(page.Results),
+		"total", page.Total)
+	return page, nil
+}
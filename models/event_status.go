@@ -0,0 +1,110 @@
+package models
+
+import (
+	"database/sql"
+	"errors"
+	"slices"
+	"time"
+
+	"example.com/event-booking-api/db"
+	"example.com/event-booking-api/utils"
+)
+
+const (
+	EventDraft     = "draft"
+	EventPublished = "published"
+	EventCancelled = "cancelled"
+	// EventCompleted is never stored. Published events are reported as
+	// completed once their last occurrence has started.
+	EventCompleted = "completed"
+)
+
+var (
+	ErrInvalidStatusTransition = errors.New("event status change is not allowed")
+	ErrEventNotOpen            = errors.New("event is not open for registration")
+)
+
+// eventTransitions lists the statuses each status may move to.
+var eventTransitions = map[string][]string{
+	EventDraft:     {EventPublished, EventCancelled},
+	EventPublished: {EventCancelled},
+}
+
+func (e *Event) CanTransitionTo(status string) bool {
+	return slices.Contains(eventTransitions[e.Status], status)
+}
+
+func (e *Event) IsDraft() bool {
+	return e.Status == EventDraft
+}
+
+// applyDerivedStatus reports published events that have ended as completed.
+func (e *Event) applyDerivedStatus(recurrenceEndsAt *time.Time) {
+	if e.Status != EventPublished {
+		return
+	}
+	end := &e.Date
+	if e.IsRecurring() {
+		end = recurrenceEndsAt
+	}
+	if end != nil && end.Before(time.Now()) {
+		e.Status = EventCompleted
+	}
+}
+
+// ChangeStatus moves the event to a new status. Cancelling keeps the event's
+// registrations but marks them as cancelled.
+func (e *Event) ChangeStatus(status string) error {
+	if !e.CanTransitionTo(status) {
+		return ErrInvalidStatusTransition
+	}
+
+	tx, err := db.DB.Begin()
+	if err != nil {
+		utils.Logger.Error("Failed to begin event status transaction", "event_id", e.ID, "error", err)
+		return err
+	}
+	defer tx.Rollback()
+
+	// Guard on the current status so concurrent changes cannot skip a step
+	query := `
+    UPDATE events SET status = $1, version = version + 1, updated_at = NOW()
+    WHERE id = $2 AND status = $3 AND deleted_at IS NULL
+    RETURNING version
+    `
+	err = tx.QueryRow(query, status, e.ID, e.Status).Scan(&e.Version)
+	if err == sql.ErrNoRows {
+		return ErrInvalidStatusTransition
+	}
+	if err != nil {
+		utils.Logger.Error("Failed to update event status",
+			"event_id", e.ID,
+			"from", e.Status,
+			"to", status,
+			"error", err)
+		return err
+	}
+
+	if status == EventCancelled {
+		query := `
+        UPDATE registrations SET status = $1
+        WHERE event_id = $2 AND status IN ($3, $4, $5)
+        `
+		_, err = tx.Exec(query, RegistrationCancelled, e.ID, RegistrationConfirmed, RegistrationWaitlisted,
+			RegistrationPending)
+		if err != nil {
+			utils.Logger.Error("Failed to cancel event registrations", "event_id", e.ID, "error", err)
+			return err
+		}
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		utils.Logger.Error("Failed to commit event status change", "event_id", e.ID, "error", err)
+		return err
+	}
+
+	utils.Logger.Debug("Event status changed", "event_id", e.ID, "from", e.Status, "to", status)
+	e.Status = status
+	return nil
+}
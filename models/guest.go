@@ -0,0 +1,64 @@
+package models
+
+import (
+	"database/sql"
+
+	"example.com/event-booking-api/db"
+	"example.com/event-booking-api/utils"
+	"github.com/lib/pq"
+)
+
+// Guest is someone a registrant brings along.
+type Guest struct {
+	Name  string  `json:"name" binding:"required,max=100"`
+	Email *string `json:"email" binding:"omitempty,email"`
+}
+
+func saveGuests(tx *sql.Tx, registrationID int64, guests []Guest) error {
+	query := "INSERT INTO registration_guests (registration_id, name, email) VALUES ($1, $2, $3)"
+	for _, g := range guests {
+		_, err := tx.Exec(query, registrationID, g.Name, g.Email)
+		if err != nil {
+			utils.Logger.Error("Failed to save registration guest", "registration_id", registrationID, "error", err)
+			return err
+		}
+	}
+	return nil
+}
+
+// getGuests loads the guests of the given registrations, keyed by
+// registration ID.
+func getGuests(registrationIDs []int64) (map[int64][]Guest, error) {
+	guests := map[int64][]Guest{}
+	if len(registrationIDs) == 0 {
+		return guests, nil
+	}
+
+	query := `
+        SELECT registration_id, name, email FROM registration_guests
+        WHERE registration_id = ANY($1)
+        ORDER BY id
+    `
+	rows, err := db.DB.Query(query, pq.Array(registrationIDs))
+	if err != nil {
+		utils.Logger.Error("Failed to query registration guests", "error", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var registrationID int64
+		var g Guest
+		var email sql.NullString
+		err := rows.Scan(&registrationID, &g.Name, &email)
+		if err != nil {
+			utils.Logger.Error("Failed to scan registration guest row", "error", err)
+			return nil, err
+		}
+		if email.Valid {
+			g.Email = &email.String
+		}
+		guests[registrationID] = append(guests[registrationID], g)
+	}
+	return guests, nil
+}
@@ -0,0 +1,64 @@
+package models
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+)
+
+var ErrInvalidPatch = errors.New("invalid merge patch")
+
+// patchField describes a member an RFC 7396 merge patch may set.
+type patchField struct {
+	// field is the Go struct field the member decodes into.
+	field string
+	// nullable fields are reset to their zero value by a null member;
+	// for the others null is an error.
+	nullable bool
+}
+
+// MergePatchResult lists what a merge patch touched.
+type MergePatchResult struct {
+	// Present holds the Go names of the fields the patch set, so that only
+	// they are validated.
+	Present []string
+	// Changed holds the JSON names of the fields whose value differs from
+	// before.
+	Changed []string
+}
+
+// applyMergePatch decodes each member of patch into the matching field of
+// dst, a pointer to a struct. Members missing from fields are rejected.
+// Patches are flat: object members replace the field as a whole.
+func applyMergePatch(dst any, fields map[string]patchField, patch map[string]json.RawMessage) (*MergePatchResult, error) {
+	target := reflect.ValueOf(dst).Elem()
+	result := &MergePatchResult{Present: []string{}, Changed: []string{}}
+
+	for name, raw := range patch {
+		pf, ok := fields[name]
+		if !ok {
+			return nil, fmt.Errorf("%w: field %q cannot be changed", ErrInvalidPatch, name)
+		}
+		field := target.FieldByName(pf.field)
+		value := reflect.New(field.Type()).Elem()
+
+		if string(raw) == "null" {
+			if !pf.nullable {
+				return nil, fmt.Errorf("%w: field %q cannot be null", ErrInvalidPatch, name)
+			}
+		} else {
+			err := json.Unmarshal(raw, value.Addr().Interface())
+			if err != nil {
+				return nil, fmt.Errorf("%w: field %q: %v", ErrInvalidPatch, name, err)
+			}
+		}
+
+		result.Present = append(result.Present, pf.field)
+		if !reflect.DeepEqual(field.Interface(), value.Interface()) {
+			field.Set(value)
+			result.Changed = append(result.Changed, name)
+		}
+	}
+	return result, nil
+}
@@ -0,0 +1,134 @@
+package models
+
+import (
+	"encoding/json"
+	"errors"
+	"slices"
+	"testing"
+)
+
+type patchTarget struct {
+	Title    string
+	Capacity *int
+	Tags     []string
+}
+
+var patchTargetFields = map[string]patchField{
+	"title":    {field: "Title"},
+	"capacity": {field: "Capacity", nullable: true},
+	"tags":     {field: "Tags", nullable: true},
+}
+
+func TestApplyMergePatch(t *testing.T) {
+	capacity := 10
+	tests := []struct {
+		name    string
+		patch   string
+		wantErr bool
+		want    patchTarget
+		present []string
+		changed []string
+	}{
+		{
+			name:    "empty patch",
+			patch:   `{}`,
+			want:    patchTarget{Title: "Meetup", Capacity: &capacity, Tags: []string{"go"}},
+			present: []string{},
+			changed: []string{},
+		},
+		{
+			name:    "set field",
+			patch:   `{"title": "Workshop"}`,
+			want:    patchTarget{Title: "Workshop", Capacity: &capacity, Tags: []string{"go"}},
+			present: []string{"Title"},
+			changed: []string{"title"},
+		},
+		{
+			name:    "same value",
+			patch:   `{"capacity": 10}`,
+			want:    patchTarget{Title: "Meetup", Capacity: &capacity, Tags: []string{"go"}},
+			present: []string{"Capacity"},
+			changed: []string{},
+		},
+		{
+			name:    "null on nullable field",
+			patch:   `{"capacity": null, "tags": null}`,
+			want:    patchTarget{Title: "Meetup"},
+			present: []string{"Capacity", "Tags"},
+			changed: []string{"capacity", "tags"},
+		},
+		{
+			name:    "null on non-nullable field",
+			patch:   `{"title": null}`,
+			wantErr: true,
+		},
+		{
+			name:    "unknown field",
+			patch:   `{"user_id": 2}`,
+			wantErr: true,
+		},
+		{
+			name:    "wrong type",
+			patch:   `{"capacity": "ten"}`,
+			wantErr: true,
+		},
+		{
+			name:    "wrong type in array",
+			patch:   `{"tags": [1, 2]}`,
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			capacity := 10
+			target := patchTarget{Title: "Meetup", Capacity: &capacity, Tags: []string{"go"}}
+
+			var patch map[string]json.RawMessage
+			err := json.Unmarshal([]byte(tt.patch), &patch)
+			if err != nil {
+				t.Fatalf("bad test patch: %v", err)
+			}
+
+			result, err := applyMergePatch(&target, patchTargetFields, patch)
+			if tt.wantErr {
+				if !errors.Is(err, ErrInvalidPatch) {
+					t.Fatalf("error = %v, want ErrInvalidPatch", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if target.Title != tt.want.Title || !slices.Equal(target.Tags, tt.want.Tags) ||
+				(target.Capacity == nil) != (tt.want.Capacity == nil) ||
+				(target.Capacity != nil && *target.Capacity != *tt.want.Capacity) {
+				t.Errorf("target = %+v, want %+v", target, tt.want)
+			}
+
+			// Patch members are applied in map order
+			slices.Sort(result.Present)
+			slices.Sort(result.Changed)
+			if !slices.Equal(result.Present, tt.present) {
+				t.Errorf("Present = %v, want %v", result.Present, tt.present)
+			}
+			if !slices.Equal(result.Changed, tt.changed) {
+				t.Errorf("Changed = %v, want %v", result.Changed, tt.changed)
+			}
+		})
+	}
+}
+
+func TestApplyMergePatchLeavesTargetOnError(t *testing.T) {
+	target := patchTarget{Title: "Meetup"}
+	patch := map[string]json.RawMessage{"title": json.RawMessage(`42`)}
+
+	_, err := applyMergePatch(&target, patchTargetFields, patch)
+	if !errors.Is(err, ErrInvalidPatch) {
+		t.Fatalf("error = %v, want ErrInvalidPatch", err)
+	}
+	if target.Title != "Meetup" {
+		t.Errorf("Title = %q after failed patch, want it unchanged", target.Title)
+	}
+}
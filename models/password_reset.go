@@ -0,0 +1,109 @@
+package models
+
+import (
+	"database/sql"
+	"errors"
+	"time"
+
+	"example.com/event-booking-api/db"
+	"example.com/event-booking-api/utils"
+)
+
+var ErrInvalidResetToken = errors.New("password reset token is invalid, expired or used")
+
+// PasswordResetDuration is how long a password reset token can be used.
+var PasswordResetDuration = time.Hour
+
+// CreatePasswordResetToken issues a token that lets the user choose a new
+// password, replacing any they were sent before. Only its hash is stored, so
+// the token is returned to the caller once.
+func (u *User) CreatePasswordResetToken() (string, error) {
+	token, err := utils.GenerateSecureToken(32)
+	if err != nil {
+		utils.Logger.Error("Failed to generate password reset token", "user_id", u.ID, "error", err)
+		return "", err
+	}
+
+	tx, err := db.DB.Begin()
+	if err != nil {
+		utils.Logger.Error("Failed to begin password reset transaction", "user_id", u.ID, "error", err)
+		return "", err
+	}
+	defer tx.Rollback()
+
+	_, err = tx.Exec("DELETE FROM password_reset_tokens WHERE user_id = $1 AND used_at IS NULL", u.ID)
+	if err != nil {
+		utils.Logger.Error("Failed to discard previous password reset tokens", "user_id", u.ID, "error", err)
+		return "", err
+	}
+
+	query := "INSERT INTO password_reset_tokens (user_id, token_hash, expires_at) VALUES ($1, $2, $3)"
+	_, err = tx.Exec(query, u.ID, utils.HashToken(token), time.Now().UTC().Add(PasswordResetDuration))
+	if err != nil {
+		utils.Logger.Error("Failed to store password reset token", "user_id", u.ID, "error", err)
+		return "", err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		utils.Logger.Error("Failed to commit password reset token", "user_id", u.ID, "error", err)
+		return "", err
+	}
+	utils.Logger.Debug("Password reset token created", "user_id", u.ID)
+	return token, nil
+}
+
+// ResetPassword sets a new password for the user the token was issued to
+// and uses up the token. Every session of the user is signed out.
+func ResetPassword(token, password string) (*User, error) {
+	hashedPassword, err := utils.HashPassword(password)
+	if err != nil {
+		utils.Logger.Error("Failed to hash password", "error", err)
+		return nil, err
+	}
+
+	tx, err := db.DB.Begin()
+	if err != nil {
+		utils.Logger.Error("Failed to begin password reset transaction", "error", err)
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	query := `
+        UPDATE password_reset_tokens SET used_at = NOW()
+        WHERE token_hash = $1 AND used_at IS NULL AND expires_at > $2
+        RETURNING user_id
+    `
+	var u User
+	err = tx.QueryRow(query, utils.HashToken(token), time.Now().UTC()).Scan(&u.ID)
+	if err == sql.ErrNoRows {
+		return nil, ErrInvalidResetToken
+	}
+	if err != nil {
+		utils.Logger.Error("Failed to use password reset token", "error", err)
+		return nil, err
+	}
+
+	query = `
+        UPDATE users SET password = $1, token_version = token_version + 1,
+            version = version + 1, updated_at = NOW()
+        WHERE id = $2 AND deleted_at IS NULL
+        RETURNING email, role, version, token_version
+    `
+	err = tx.QueryRow(query, hashedPassword, u.ID).Scan(&u.Email, &u.Role, &u.Version, &u.TokenVersion)
+	if err == sql.ErrNoRows {
+		return nil, ErrInvalidResetToken
+	}
+	if err != nil {
+		utils.Logger.Error("Failed to reset password", "user_id", u.ID, "error", err)
+		return nil, err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		utils.Logger.Error("Failed to commit password reset", "user_id", u.ID, "error", err)
+		return nil, err
+	}
+	utils.Logger.Debug("Password reset", "user_id", u.ID)
+	return &u, nil
+}
@@ -0,0 +1,288 @@
+package models
+
+import (
+	"database/sql"
+	"errors"
+	"slices"
+
+	"example.com/event-booking-api/db"
+	"example.com/event-booking-api/utils"
+	"github.com/lib/pq"
+)
+
+// The permission catalogue. Owners can always manage their own events,
+// registrations and account; the ":any" permissions extend that to everyone
+// else's.
+const (
+	PermEventsReadAny           = "events:read:any"
+	PermEventsUpdateAny         = "events:update:any"
+	PermEventsDeleteAny         = "events:delete:any"
+	PermRegistrationsReadAny    = "registrations:read:any"
+	PermRegistrationsReviewAny  = "registrations:review:any"
+	PermRegistrationsCheckInAny = "registrations:check_in:any"
+	PermUsersReadAny            = "users:read:any"
+	PermUsersUpdateAny          = "users:update:any"
+	PermUsersDeleteAny          = "users:delete:any"
+	PermUsersAssignRole         = "users:assign_role"
+	PermRolesManage             = "roles:manage"
+	PermCategoriesManage        = "categories:manage"
+	PermVenuesDeleteAny         = "venues:delete:any"
+	PermServiceAccountsManage   = "service_accounts:manage"
+)
+
+// Permission describes an entry of the catalogue.
+type Permission struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+}
+
+// Permissions is the catalogue, which roles pick their permissions from.
+var Permissions = []Permission{
+	{PermEventsReadAny, "See any event, including drafts"},
+	{PermEventsUpdateAny, "Change any event, with its occurrences, tickets, promo codes and questions"},
+	{PermEventsDeleteAny, "Delete and restore any event"},
+	{PermRegistrationsReadAny, "See and export the registrations and tickets of any event or user"},
+	{PermRegistrationsReviewAny, "Approve and reject applications to any event"},
+	{PermRegistrationsCheckInAny, "Check attendees in at any event"},
+	{PermUsersReadAny, "See any user"},
+	{PermUsersUpdateAny, "Change any user's account"},
+	{PermUsersDeleteAny, "Delete and restore any user"},
+	{PermUsersAssignRole, "Change the role of a user"},
+	{PermRolesManage, "Create, change and delete roles"},
+	{PermCategoriesManage, "Create, change and delete categories"},
+	{PermVenuesDeleteAny, "Delete any venue"},
+	{PermServiceAccountsManage, "Create service accounts and manage their API keys"},
+}
+
+var (
+	ErrUnknownRole       = errors.New("role does not exist")
+	ErrDuplicateRole     = errors.New("a role with this name already exists")
+	ErrBuiltinRole       = errors.New("built-in roles cannot be changed")
+	ErrRoleInUse         = errors.New("role is assigned to users")
+	ErrUnknownPermission = errors.New("permission is not in the catalogue")
+)
+
+// PermissionSet holds the permissions of a role.
+type PermissionSet map[string]bool
+
+func (s PermissionSet) Has(permission string) bool {
+	return s[permission]
+}
+
+func IsKnownPermission(name string) bool {
+	return slices.ContainsFunc(Permissions, func(p Permission) bool {
+		return p.Name == name
+	})
+}
+
+type Role struct {
+	Name        string   `json:"name" binding:"required,max=50"`
+	Description string   `json:"description" binding:"max=200"`
+	Builtin     bool     `json:"builtin"`
+	Permissions []string `json:"permissions" binding:"required"`
+}
+
+// GetRolePermissions returns the permissions granted to the role. Unknown
+// roles have none.
+func GetRolePermissions(role string) (PermissionSet, error) {
+	rows, err := db.DB.Query("SELECT permission FROM role_permissions WHERE role = $1", role)
+	if err != nil {
+		utils.Logger.Error("Failed to query role permissions", "role", role, "error", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	permissions := PermissionSet{}
+	for rows.Next() {
+		var permission string
+		err := rows.Scan(&permission)
+		if err != nil {
+			utils.Logger.Error("Failed to scan role permission", "role", role, "error", err)
+			return nil, err
+		}
+		permissions[permission] = true
+	}
+	return permissions, nil
+}
+
+func (r *Role) validatePermissions() error {
+	for _, permission := range r.Permissions {
+		if !IsKnownPermission(permission) {
+			return ErrUnknownPermission
+		}
+	}
+	slices.Sort(r.Permissions)
+	r.Permissions = slices.Compact(r.Permissions)
+	return nil
+}
+
+func savePermissions(tx *sql.Tx, role string, permissions []string) error {
+	_, err := tx.Exec("DELETE FROM role_permissions WHERE role = $1", role)
+	if err != nil {
+		utils.Logger.Error("Failed to clear role permissions", "role", role, "error", err)
+		return err
+	}
+	for _, permission := range permissions {
+		_, err = tx.Exec("INSERT INTO role_permissions (role, permission) VALUES ($1, $2)", role, permission)
+		if err != nil {
+			utils.Logger.Error("Failed to save role permission", "role", role, "permission", permission, "error", err)
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *Role) Save() error {
+	err := r.validatePermissions()
+	if err != nil {
+		return err
+	}
+	r.Builtin = false
+
+	tx, err := db.DB.Begin()
+	if err != nil {
+		utils.Logger.Error("Failed to begin role save transaction", "role", r.Name, "error", err)
+		return err
+	}
+	defer tx.Rollback()
+
+	_, err = tx.Exec("INSERT INTO roles (name, description) VALUES ($1, $2)", r.Name, r.Description)
+	if isUniqueViolation(err) {
+		return ErrDuplicateRole
+	}
+	if err != nil {
+		utils.Logger.Error("Failed to save role to database", "role", r.Name, "error", err)
+		return err
+	}
+
+	err = savePermissions(tx, r.Name, r.Permissions)
+	if err != nil {
+		return err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		utils.Logger.Error("Failed to commit role save", "role", r.Name, "error", err)
+		return err
+	}
+	utils.Logger.Debug("Role saved to database", "role", r.Name, "permissions", len(r.Permissions))
+	return nil
+}
+
+// Update stores the role's description and replaces its permissions. Users
+// holding the role get the new permissions on their next request.
+func (r *Role) Update() error {
+	err := r.validatePermissions()
+	if err != nil {
+		return err
+	}
+
+	tx, err := db.DB.Begin()
+	if err != nil {
+		utils.Logger.Error("Failed to begin role update transaction", "role", r.Name, "error", err)
+		return err
+	}
+	defer tx.Rollback()
+
+	query := "UPDATE roles SET description = $1 WHERE name = $2 AND NOT builtin"
+	res, err := tx.Exec(query, r.Description, r.Name)
+	if err != nil {
+		utils.Logger.Error("Failed to update role in database", "role", r.Name, "error", err)
+		return err
+	}
+	updated, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if updated == 0 {
+		return ErrBuiltinRole
+	}
+
+	err = savePermissions(tx, r.Name, r.Permissions)
+	if err != nil {
+		return err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		utils.Logger.Error("Failed to commit role update", "role", r.Name, "error", err)
+		return err
+	}
+	utils.Logger.Debug("Role updated in database", "role", r.Name, "permissions", len(r.Permissions))
+	return nil
+}
+
+// Delete removes a role that no user holds.
+func (r *Role) Delete() error {
+	if r.Builtin {
+		return ErrBuiltinRole
+	}
+	_, err := db.DB.Exec("DELETE FROM roles WHERE name = $1 AND NOT builtin", r.Name)
+	if isForeignKeyViolation(err) {
+		return ErrRoleInUse
+	}
+	if err != nil {
+		utils.Logger.Error("Failed to delete role from database", "role", r.Name, "error", err)
+		return err
+	}
+	utils.Logger.Debug("Role deleted from database", "role", r.Name)
+	return nil
+}
+
+func GetRoles() ([]Role, error) {
+	query := `
+        SELECT r.name, r.description, r.builtin,
+            COALESCE(array_agg(p.permission ORDER BY p.permission) FILTER (WHERE p.permission IS NOT NULL), '{}')
+        FROM roles r
+        LEFT JOIN role_permissions p ON p.role = r.name
+        GROUP BY r.name
+        ORDER BY r.name
+    `
+	rows, err := db.DB.Query(query)
+	if err != nil {
+		utils.Logger.Error("Failed to query roles", "error", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	roles := []Role{}
+	for rows.Next() {
+		var r Role
+		err := rows.Scan(&r.Name, &r.Description, &r.Builtin, pq.Array(&r.Permissions))
+		if err != nil {
+			utils.Logger.Error("Failed to scan role row", "error", err)
+			return nil, err
+		}
+		roles = append(roles, r)
+	}
+
+	utils.Logger.Debug("Retrieved roles", "count", len(roles))
+	return roles, nil
+}
+
+// GetRole loads a role with its permissions. It returns ErrUnknownRole if
+// there is no such role.
+func GetRole(name string) (*Role, error) {
+	r := Role{Name: name}
+	err := db.DB.QueryRow("SELECT description, builtin FROM roles WHERE name = $1", name).Scan(&r.Description, &r.Builtin)
+	if err == sql.ErrNoRows {
+		return nil, ErrUnknownRole
+	}
+	if err != nil {
+		utils.Logger.Error("Failed to get role", "role", name, "error", err)
+		return nil, err
+	}
+
+	permissions, err := GetRolePermissions(name)
+	if err != nil {
+		return nil, err
+	}
+	r.Permissions = []string{}
+	for permission := range permissions {
+		r.Permissions = append(r.Permissions, permission)
+	}
+	slices.Sort(r.Permissions)
+
+	utils.Logger.Debug("Retrieved role", "role", name)
+	return &r, nil
+}
@@ -0,0 +1,319 @@
+package models
+
+import (
+	"database/sql"
+	"errors"
+	"slices"
+	"strings"
+	"time"
+
+	"example.com/event-booking-api/db"
+	"example.com/event-booking-api/utils"
+	"github.com/lib/pq"
+)
+
+const (
+	DiscountPercent = "percent"
+	DiscountFixed   = "fixed"
+)
+
+var (
+	ErrDuplicatePromoCode     = errors.New("a promo code with this code already exists for the event")
+	ErrInvalidDiscount        = errors.New("a percentage discount cannot exceed 100")
+	ErrPromoCodeInUse         = errors.New("promo code has been redeemed")
+	ErrUnknownPromoCode       = errors.New("promo code does not exist for this event")
+	ErrPromoCodeExpired       = errors.New("promo code has expired")
+	ErrPromoCodeExhausted     = errors.New("promo code has no uses left")
+	ErrPromoCodeUserLimit     = errors.New("promo code has already been used the maximum number of times by this user")
+	ErrPromoCodeNotApplicable = errors.New("promo code does not apply to this ticket type")
+)
+
+// promoCodeColumns lists the columns scanned by scanPromoCode, in order.
+const promoCodeColumns = "id, event_id, code, discount_type, discount_value, max_uses, max_uses_per_user, expires_at, ticket_type_ids"
+
+// PromoCode is a discount code for an event. The limits and the expiry are
+// optional; an empty TicketTypeIDs applies the code to every ticket type.
+type PromoCode struct {
+	ID             int64      `json:"id"`
+	EventID        int64      `json:"event_id"`
+	Code           string     `json:"code" binding:"required,max=50"`
+	DiscountType   string     `json:"discount_type" binding:"required,oneof=percent fixed"`
+	DiscountValue  int        `json:"discount_value" binding:"required,gt=0"`
+	MaxUses        *int       `json:"max_uses" binding:"omitempty,gt=0"`
+	MaxUsesPerUser *int       `json:"max_uses_per_user" binding:"omitempty,gt=0"`
+	ExpiresAt      *time.Time `json:"expires_at"`
+	TicketTypeIDs  []int64    `json:"ticket_type_ids" binding:"omitempty,max=50,dive,gt=0"`
+}
+
+// PromoCodeUsage reports how much a code has been used. Redemptions of
+// rejected or cancelled registrations do not count.
+type PromoCodeUsage struct {
+	PromoCode
+	Uses          int   `json:"uses"`
+	Users         int   `json:"users"`
+	DiscountCents int64 `json:"discount_cents"`
+}
+
+// NormalizePromoCode trims and upper-cases a code so that lookups ignore case.
+func NormalizePromoCode(code string) string {
+	return strings.ToUpper(strings.TrimSpace(code))
+}
+
+func (p *PromoCode) validate(q queryer) error {
+	p.Code = NormalizePromoCode(p.Code)
+	if p.DiscountType == DiscountPercent && p.DiscountValue > 100 {
+		return ErrInvalidDiscount
+	}
+	if p.TicketTypeIDs == nil {
+		p.TicketTypeIDs = []int64{}
+	}
+	slices.Sort(p.TicketTypeIDs)
+	p.TicketTypeIDs = slices.Compact(p.TicketTypeIDs)
+	if len(p.TicketTypeIDs) == 0 {
+		return nil
+	}
+
+	query := "SELECT id FROM ticket_types WHERE event_id = $1 AND id = ANY($2)"
+	rows, err := q.Query(query, p.EventID, pq.Array(p.TicketTypeIDs))
+	if err != nil {
+		utils.Logger.Error("Failed to check promo code ticket types", "event_id", p.EventID, "error", err)
+		return err
+	}
+	defer rows.Close()
+	found := 0
+	for rows.Next() {
+		found++
+	}
+	if found != len(p.TicketTypeIDs) {
+		return ErrUnknownTicketType
+	}
+	return nil
+}
+
+// Discount works out the discount on a price, never more than the price.
+func (p *PromoCode) Discount(priceCents int) int {
+	discount := p.DiscountValue
+	if p.DiscountType == DiscountPercent {
+		discount = priceCents * p.DiscountValue / 100
+	}
+	return min(discount, priceCents)
+}
+
+func (p *PromoCode) appliesTo(ticketTypeID *int64) bool {
+	if len(p.TicketTypeIDs) == 0 {
+		return true
+	}
+	return ticketTypeID != nil && slices.Contains(p.TicketTypeIDs, *ticketTypeID)
+}
+
+func (p *PromoCode) Save() error {
+	err := p.validate(db.DB)
+	if err != nil {
+		return err
+	}
+
+	query := `
+    INSERT INTO promo_codes (event_id, code, discount_type, discount_value, max_uses, max_uses_per_user,
+        expires_at, ticket_type_ids)
+    VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+    RETURNING id
+    `
+	err = db.DB.QueryRow(query, p.EventID, p.Code, p.DiscountType, p.DiscountValue, p.MaxUses,
+		p.MaxUsesPerUser, p.ExpiresAt, pq.Array(p.TicketTypeIDs)).Scan(&p.ID)
+	if isUniqueViolation(err) {
+		return ErrDuplicatePromoCode
+	}
+	if err != nil {
+		utils.Logger.Error("Failed to save promo code to database", "event_id", p.EventID, "error", err)
+		return err
+	}
+	utils.Logger.Debug("Promo code saved to database", "promo_code_id", p.ID, "event_id", p.EventID)
+	return nil
+}
+
+// Update changes the code. Lowering a limit below the uses so far is
+// allowed; it only stops further redemptions.
+func (p *PromoCode) Update() error {
+	err := p.validate(db.DB)
+	if err != nil {
+		return err
+	}
+
+	query := `
+    UPDATE promo_codes
+    SET code = $1, discount_type = $2, discount_value = $3, max_uses = $4, max_uses_per_user = $5,
+        expires_at = $6, ticket_type_ids = $7
+    WHERE id = $8 AND event_id = $9
+    `
+	_, err = db.DB.Exec(query, p.Code, p.DiscountType, p.DiscountValue, p.MaxUses, p.MaxUsesPerUser,
+		p.ExpiresAt, pq.Array(p.TicketTypeIDs), p.ID, p.EventID)
+	if isUniqueViolation(err) {
+		return ErrDuplicatePromoCode
+	}
+	if err != nil {
+		utils.Logger.Error("Failed to update promo code in database", "promo_code_id", p.ID, "error", err)
+		return err
+	}
+	utils.Logger.Debug("Promo code updated in database", "promo_code_id", p.ID, "event_id", p.EventID)
+	return nil
+}
+
+func (p *PromoCode) Delete() error {
+	query := `DELETE FROM promo_codes WHERE id = $1 AND event_id = $2`
+	_, err := db.DB.Exec(query, p.ID, p.EventID)
+	if isForeignKeyViolation(err) {
+		return ErrPromoCodeInUse
+	}
+	if err != nil {
+		utils.Logger.Error("Failed to delete promo code from database", "promo_code_id", p.ID, "error", err)
+		return err
+	}
+	utils.Logger.Debug("Promo code deleted from database", "promo_code_id", p.ID, "event_id", p.EventID)
+	return nil
+}
+
+func scanPromoCode(row rowScanner, extra ...any) (*PromoCode, error) {
+	var p PromoCode
+	var maxUses, maxUsesPerUser sql.NullInt64
+	var expiresAt sql.NullTime
+	var ticketTypeIDs pq.Int64Array
+	dest := []any{&p.ID, &p.EventID, &p.Code, &p.DiscountType, &p.DiscountValue, &maxUses, &maxUsesPerUser,
+		&expiresAt, &ticketTypeIDs}
+	err := row.Scan(append(dest, extra...)...)
+	if err != nil {
+		return nil, err
+	}
+	if maxUses.Valid {
+		m := int(maxUses.Int64)
+		p.MaxUses = &m
+	}
+	if maxUsesPerUser.Valid {
+		m := int(maxUsesPerUser.Int64)
+		p.MaxUsesPerUser = &m
+	}
+	if expiresAt.Valid {
+		p.ExpiresAt = &expiresAt.Time
+	}
+	p.TicketTypeIDs = []int64(ticketTypeIDs)
+	if p.TicketTypeIDs == nil {
+		p.TicketTypeIDs = []int64{}
+	}
+	return &p, nil
+}
+
+// GetPromoCode looks up a promo code of the given event. It returns
+// ErrUnknownPromoCode if the event has no such code.
+func GetPromoCode(eventID, id int64) (*PromoCode, error) {
+	query := "SELECT " + promoCodeColumns + " FROM promo_codes WHERE id = $1 AND event_id = $2"
+	p, err := scanPromoCode(db.DB.QueryRow(query, id, eventID))
+	if err == sql.ErrNoRows {
+		return nil, ErrUnknownPromoCode
+	}
+	if err != nil {
+		utils.Logger.Error("Failed to get promo code", "event_id", eventID, "promo_code_id", id, "error", err)
+		return nil, err
+	}
+	return p, nil
+}
+
+// GetPromoCodeUsage lists the event's promo codes with how much each has
+// been used.
+func GetPromoCodeUsage(eventID int64) ([]PromoCodeUsage, error) {
+	query := `
+        SELECT ` + promoCodeColumns + `,
+            COUNT(u.redemption_id), COUNT(DISTINCT u.user_id), COALESCE(SUM(u.discount_cents), 0)
+        FROM promo_codes
+        LEFT JOIN (
+            SELECT pr.id AS redemption_id, pr.promo_code_id, pr.user_id, pr.discount_cents
+            FROM promo_redemptions pr
+            JOIN registrations r ON r.id = pr.registration_id
+            WHERE r.status NOT IN ($2, $3)
+        ) u ON u.promo_code_id = promo_codes.id
+        WHERE event_id = $1
+        GROUP BY promo_codes.id
+        ORDER BY code
+    `
+	rows, err := db.DB.Query(query, eventID, RegistrationRejected, RegistrationCancelled)
+	if err != nil {
+		utils.Logger.Error("Failed to query promo codes", "event_id", eventID, "error", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	usage := []PromoCodeUsage{}
+	for rows.Next() {
+		var u PromoCodeUsage
+		p, err := scanPromoCode(rows, &u.Uses, &u.Users, &u.DiscountCents)
+		if err != nil {
+			utils.Logger.Error("Failed to scan promo code row", "error", err)
+			return nil, err
+		}
+		u.PromoCode = *p
+		usage = append(usage, u)
+	}
+
+	utils.Logger.Debug("Retrieved promo codes", "event_id", eventID, "count", len(usage))
+	return usage, nil
+}
+
+// redeemPromoCode applies a code to a new registration and records the
+// redemption. The code's row is locked first, so concurrent registrations
+// using it are counted one after the other and cannot exceed its limits.
+// It returns the discount in cents.
+func redeemPromoCode(tx *sql.Tx, code string, r *Registration, ticketType *TicketType) (int, error) {
+	query := "SELECT " + promoCodeColumns + " FROM promo_codes WHERE event_id = $1 AND code = $2 FOR UPDATE"
+	p, err := scanPromoCode(tx.QueryRow(query, r.EventID, NormalizePromoCode(code)))
+	if err == sql.ErrNoRows {
+		return 0, ErrUnknownPromoCode
+	}
+	if err != nil {
+		utils.Logger.Error("Failed to look up promo code", "event_id", r.EventID, "error", err)
+		return 0, err
+	}
+
+	if p.ExpiresAt != nil && !time.Now().Before(*p.ExpiresAt) {
+		return 0, ErrPromoCodeExpired
+	}
+	if !p.appliesTo(r.TicketTypeID) {
+		return 0, ErrPromoCodeNotApplicable
+	}
+
+	query = `
+        SELECT COUNT(*), COUNT(*) FILTER (WHERE pr.user_id = $2)
+        FROM promo_redemptions pr
+        JOIN registrations r ON r.id = pr.registration_id
+        WHERE pr.promo_code_id = $1 AND r.status NOT IN ($3, $4)
+    `
+	var uses, userUses int
+	err = tx.QueryRow(query, p.ID, r.UserID, RegistrationRejected, RegistrationCancelled).Scan(&uses, &userUses)
+	if err != nil {
+		utils.Logger.Error("Failed to count promo code uses", "promo_code_id", p.ID, "error", err)
+		return 0, err
+	}
+	if p.MaxUses != nil && uses >= *p.MaxUses {
+		return 0, ErrPromoCodeExhausted
+	}
+	if p.MaxUsesPerUser != nil && userUses >= *p.MaxUsesPerUser {
+		return 0, ErrPromoCodeUserLimit
+	}
+
+	price := 0
+	if ticketType != nil {
+		price = ticketType.PriceCents * r.PartySize
+	}
+	discount := p.Discount(price)
+
+	query = `
+    INSERT INTO promo_redemptions (promo_code_id, registration_id, user_id, price_cents, discount_cents)
+    VALUES ($1, $2, $3, $4, $5)
+    `
+	_, err = tx.Exec(query, p.ID, r.ID, r.UserID, price, discount)
+	if err != nil {
+		utils.Logger.Error("Failed to save promo code redemption",
+			"promo_code_id", p.ID,
+			"registration_id", r.ID,
+			"error", err)
+		return 0, err
+	}
+	return discount, nil
+}
@@ -0,0 +1,401 @@
+package models
+
+import (
+	"cmp"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"example.com/event-booking-api/db"
+	"example.com/event-booking-api/utils"
+	"github.com/lib/pq"
+)
+
+const (
+	QuestionText         = "text"
+	QuestionNumber       = "number"
+	QuestionSingleChoice = "single_choice"
+	QuestionMultiChoice  = "multi_choice"
+
+	// MaxTextAnswerLength caps free-text answers, in characters.
+	MaxTextAnswerLength = 1000
+)
+
+var (
+	ErrInvalidQuestion = errors.New("invalid question")
+	ErrUnknownQuestion = errors.New("question does not belong to this event")
+	ErrInvalidAnswers  = errors.New("invalid answers")
+)
+
+// Question is one field of an event's registration form.
+type Question struct {
+	ID       int64    `json:"id"`
+	Label    string   `json:"label" binding:"required,max=200"`
+	Type     string   `json:"type" binding:"required,oneof=text number single_choice multi_choice"`
+	Required bool     `json:"required"`
+	Options  []string `json:"options" binding:"omitempty,max=50,dive,required,max=100"`
+}
+
+// Answer is a registrant's answer to one question.
+type Answer struct {
+	QuestionID int64           `json:"question_id"`
+	Label      string          `json:"label"`
+	Value      json.RawMessage `json:"value"`
+}
+
+// AnswerError describes what is wrong with the answer to one question.
+type AnswerError struct {
+	QuestionID int64  `json:"question_id"`
+	Message    string `json:"message"`
+}
+
+// AnswersError collects every problem found in a set of answers.
+type AnswersError struct {
+	Problems []AnswerError
+}
+
+func (e *AnswersError) Error() string {
+	return fmt.Sprintf("%d invalid answers", len(e.Problems))
+}
+
+func (e *AnswersError) Is(target error) bool {
+	return target == ErrInvalidAnswers
+}
+
+func (q *Question) isChoice() bool {
+	return q.Type == QuestionSingleChoice || q.Type == QuestionMultiChoice
+}
+
+func (q *Question) validate() error {
+	if q.isChoice() && len(q.Options) == 0 {
+		return fmt.Errorf("%w: %q needs at least one option", ErrInvalidQuestion, q.Label)
+	}
+	if !q.isChoice() && len(q.Options) > 0 {
+		return fmt.Errorf("%w: %q cannot have options", ErrInvalidQuestion, q.Label)
+	}
+	for i, option := range q.Options {
+		if slices.Contains(q.Options[:i], option) {
+			return fmt.Errorf("%w: %q lists option %q twice", ErrInvalidQuestion, q.Label, option)
+		}
+	}
+	return nil
+}
+
+// normalizeAnswer checks a raw answer against the question and returns the
+// value to store. A nil result means the question was left unanswered.
+func (q *Question) normalizeAnswer(raw json.RawMessage) (any, string) {
+	if len(raw) == 0 || string(raw) == "null" {
+		if q.Required {
+			return nil, "an answer is required"
+		}
+		return nil, ""
+	}
+
+	switch q.Type {
+	case QuestionText:
+		var text string
+		if json.Unmarshal(raw, &text) != nil {
+			return nil, "must be a string"
+		}
+		text = strings.TrimSpace(text)
+		if text == "" {
+			if q.Required {
+				return nil, "an answer is required"
+			}
+			return nil, ""
+		}
+		if len([]rune(text)) > MaxTextAnswerLength {
+			return nil, fmt.Sprintf("must be at most %d characters", MaxTextAnswerLength)
+		}
+		return text, ""
+
+	case QuestionNumber:
+		var number float64
+		if json.Unmarshal(raw, &number) != nil {
+			return nil, "must be a number"
+		}
+		return number, ""
+
+	case QuestionSingleChoice:
+		var choice string
+		if json.Unmarshal(raw, &choice) != nil {
+			return nil, "must be one of the options"
+		}
+		if !slices.Contains(q.Options, choice) {
+			return nil, "must be one of the options"
+		}
+		return choice, ""
+
+	case QuestionMultiChoice:
+		var choices []string
+		if json.Unmarshal(raw, &choices) != nil {
+			return nil, "must be a list of options"
+		}
+		selected := []string{}
+		for _, choice := range choices {
+			if !slices.Contains(q.Options, choice) {
+				return nil, fmt.Sprintf("%q is not one of the options", choice)
+			}
+			if !slices.Contains(selected, choice) {
+				selected = append(selected, choice)
+			}
+		}
+		if len(selected) == 0 {
+			if q.Required {
+				return nil, "pick at least one option"
+			}
+			return nil, ""
+		}
+		return selected, ""
+	}
+	return nil, "unsupported question type"
+}
+
+// invalidatesAnswersTo reports whether answers given to the stored version
+// of the question may not be valid for q.
+func (q *Question) invalidatesAnswersTo(stored *Question) bool {
+	if q.Type != stored.Type {
+		return true
+	}
+	for _, option := range stored.Options {
+		if !slices.Contains(q.Options, option) {
+			return true
+		}
+	}
+	return false
+}
+
+// ReplaceQuestions makes the given list the event's registration form.
+// Questions with an ID are updated in place and keep their answers, unless
+// their type changes or an option is dropped, which would leave answers that
+// no longer fit; questions without one are added, and questions left out are
+// removed together with their answers.
+func ReplaceQuestions(eventID int64, questions []Question) ([]Question, error) {
+	if questions == nil {
+		questions = []Question{}
+	}
+	for i := range questions {
+		err := questions[i].validate()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	tx, err := db.DB.Begin()
+	if err != nil {
+		utils.Logger.Error("Failed to begin question update transaction", "event_id", eventID, "error", err)
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	keep := []int64{}
+	for _, q := range questions {
+		if q.ID != 0 {
+			keep = append(keep, q.ID)
+		}
+	}
+	query := "DELETE FROM event_questions WHERE event_id = $1 AND NOT (id = ANY($2))"
+	_, err = tx.Exec(query, eventID, pq.Array(keep))
+	if err != nil {
+		utils.Logger.Error("Failed to remove event questions", "event_id", eventID, "error", err)
+		return nil, err
+	}
+
+	for i := range questions {
+		q := &questions[i]
+		if q.Options == nil {
+			q.Options = []string{}
+		}
+		options, err := json.Marshal(q.Options)
+		if err != nil {
+			return nil, err
+		}
+
+		if q.ID == 0 {
+			query := `
+            INSERT INTO event_questions (event_id, label, type, required, options, position)
+            VALUES ($1, $2, $3, $4, $5, $6)
+            RETURNING id
+            `
+			err = tx.QueryRow(query, eventID, q.Label, q.Type, q.Required, options, i).Scan(&q.ID)
+		} else {
+			var stored Question
+			var storedOptions []byte
+			query := "SELECT type, options FROM event_questions WHERE id = $1 AND event_id = $2 FOR UPDATE"
+			err = tx.QueryRow(query, q.ID, eventID).Scan(&stored.Type, &storedOptions)
+			if err == sql.ErrNoRows {
+				return nil, ErrUnknownQuestion
+			}
+			if err != nil {
+				utils.Logger.Error("Failed to load event question", "event_id", eventID, "question_id", q.ID, "error", err)
+				return nil, err
+			}
+			err = json.Unmarshal(storedOptions, &stored.Options)
+			if err != nil {
+				return nil, err
+			}
+			if q.invalidatesAnswersTo(&stored) {
+				_, err = tx.Exec("DELETE FROM registration_answers WHERE question_id = $1", q.ID)
+				if err != nil {
+					utils.Logger.Error("Failed to remove outdated answers", "question_id", q.ID, "error", err)
+					return nil, err
+				}
+				utils.Logger.Info("Removed answers to changed question", "event_id", eventID, "question_id", q.ID)
+			}
+
+			query = `
+            UPDATE event_questions
+            SET label = $1, type = $2, required = $3, options = $4, position = $5
+            WHERE id = $6 AND event_id = $7
+            RETURNING id
+            `
+			err = tx.QueryRow(query, q.Label, q.Type, q.Required, options, i, q.ID, eventID).Scan(&q.ID)
+			if err == sql.ErrNoRows {
+				return nil, ErrUnknownQuestion
+			}
+		}
+		if err != nil {
+			utils.Logger.Error("Failed to save event question", "event_id", eventID, "question_id", q.ID, "error", err)
+			return nil, err
+		}
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		utils.Logger.Error("Failed to commit event questions", "event_id", eventID, "error", err)
+		return nil, err
+	}
+	utils.Logger.Debug("Event questions replaced", "event_id", eventID, "count", len(questions))
+	return questions, nil
+}
+
+func GetQuestionsByEventID(eventID int64) ([]Question, error) {
+	return queryQuestions(db.DB, eventID)
+}
+
+// queryer is satisfied by both *sql.DB and *sql.Tx.
+type queryer interface {
+	Query(query string, args ...any) (*sql.Rows, error)
+}
+
+func queryQuestions(q queryer, eventID int64) ([]Question, error) {
+	query := `
+        SELECT id, label, type, required, options FROM event_questions
+        WHERE event_id = $1
+        ORDER BY position, id
+    `
+	rows, err := q.Query(query, eventID)
+	if err != nil {
+		utils.Logger.Error("Failed to query event questions", "event_id", eventID, "error", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	questions := []Question{}
+	for rows.Next() {
+		var question Question
+		var options []byte
+		err := rows.Scan(&question.ID, &question.Label, &question.Type, &question.Required, &options)
+		if err != nil {
+			utils.Logger.Error("Failed to scan event question row", "error", err)
+			return nil, err
+		}
+		err = json.Unmarshal(options, &question.Options)
+		if err != nil {
+			return nil, err
+		}
+		questions = append(questions, question)
+	}
+	return questions, nil
+}
+
+// saveAnswers validates the answers against the event's questions and stores
+// them with the registration. Every problem is reported at once through an
+// *AnswersError.
+func saveAnswers(tx *sql.Tx, eventID, registrationID int64, answers map[int64]json.RawMessage) error {
+	questions, err := queryQuestions(tx, eventID)
+	if err != nil {
+		return err
+	}
+
+	problems := []AnswerError{}
+	for id := range answers {
+		if !slices.ContainsFunc(questions, func(q Question) bool { return q.ID == id }) {
+			problems = append(problems, AnswerError{QuestionID: id, Message: "unknown question"})
+		}
+	}
+
+	values := map[int64][]byte{}
+	for _, q := range questions {
+		value, problem := q.normalizeAnswer(answers[q.ID])
+		if problem != "" {
+			problems = append(problems, AnswerError{QuestionID: q.ID, Message: problem})
+			continue
+		}
+		if value == nil {
+			continue
+		}
+		encoded, err := json.Marshal(value)
+		if err != nil {
+			return err
+		}
+		values[q.ID] = encoded
+	}
+	if len(problems) > 0 {
+		slices.SortFunc(problems, func(a, b AnswerError) int { return cmp.Compare(a.QuestionID, b.QuestionID) })
+		return &AnswersError{Problems: problems}
+	}
+
+	query := "INSERT INTO registration_answers (registration_id, question_id, value) VALUES ($1, $2, $3)"
+	for questionID, value := range values {
+		_, err := tx.Exec(query, registrationID, questionID, value)
+		if err != nil {
+			utils.Logger.Error("Failed to save registration answer",
+				"registration_id", registrationID,
+				"question_id", questionID,
+				"error", err)
+			return err
+		}
+	}
+	return nil
+}
+
+// getAnswers loads the answers of the given registrations, keyed by
+// registration ID and in form order.
+func getAnswers(registrationIDs []int64) (map[int64][]Answer, error) {
+	answers := map[int64][]Answer{}
+	if len(registrationIDs) == 0 {
+		return answers, nil
+	}
+
+	query := `
+        SELECT a.registration_id, a.question_id, q.label, a.value
+        FROM registration_answers a
+        JOIN event_questions q ON q.id = a.question_id
+        WHERE a.registration_id = ANY($1)
+        ORDER BY q.position, q.id
+    `
+	rows, err := db.DB.Query(query, pq.Array(registrationIDs))
+	if err != nil {
+		utils.Logger.Error("Failed to query registration answers", "error", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var registrationID int64
+		var a Answer
+		var value []byte
+		err := rows.Scan(&registrationID, &a.QuestionID, &a.Label, &value)
+		if err != nil {
+			utils.Logger.Error("Failed to scan registration answer row", "error", err)
+			return nil, err
+		}
+		a.Value = value
+		answers[registrationID] = append(answers[registrationID], a)
+	}
+	return answers, nil
+}
@@ -0,0 +1,426 @@
+package models
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"example.com/event-booking-api/db"
+	"example.com/event-booking-api/utils"
+	"github.com/lib/pq"
+	"github.com/teambition/rrule-go"
+)
+
+const (
+	// DefaultOccurrenceWindow is how far ahead recurring events are expanded
+	// when the caller does not give an explicit date range.
+	DefaultOccurrenceWindow = 90 * 24 * time.Hour
+	// MaxOccurrences caps how many occurrences are expanded per event.
+	MaxOccurrences = 100
+
+	// MaxRecurrenceCount caps how many occurrences a rule with COUNT or
+	// UNTIL may produce, and MaxRecurrenceSpan how far UNTIL may lie after
+	// the first occurrence.
+	MaxRecurrenceCount = 1000
+	MaxRecurrenceSpan  = 10 * 366 * 24 * time.Hour
+
+	// maxRecurrenceSteps caps how many occurrences are walked through to
+	// find a date, so rules that never end cannot be walked forever.
+	maxRecurrenceSteps = 100000
+)
+
+var (
+	ErrInvalidRecurrenceRule = errors.New("invalid recurrence rule")
+	ErrInvalidOccurrence     = errors.New("date is not an occurrence of this event")
+)
+
+// Occurrence is a single instance of a recurring event. OriginalDate is the
+// start produced by the recurrence rule and identifies the occurrence even
+// when it has been moved to another date.
+type Occurrence struct {
+	OriginalDate time.Time `json:"original_date"`
+	Date         time.Time `json:"date"`
+	Title        string    `json:"title"`
+	Description  string    `json:"description"`
+	Location     string    `json:"location"`
+	Modified     bool      `json:"modified"`
+}
+
+// OccurrenceOverride changes a single occurrence of a recurring event. Nil
+// fields keep the value from the series.
+type OccurrenceOverride struct {
+	ID             int64      `json:"id"`
+	EventID        int64      `json:"event_id"`
+	OccurrenceDate time.Time  `json:"occurrence_date"`
+	Title          *string    `json:"title" binding:"omitempty,min=1"`
+	Description    *string    `json:"description" binding:"omitempty,min=1"`
+	Location       *string    `json:"location" binding:"omitempty,min=1"`
+	Date           *time.Time `json:"date"`
+}
+
+// timestamp columns hold UTC wall-clock times without a zone
+const pgTimestampLayout = "2006-01-02 15:04:05.999999"
+
+func (e *Event) IsRecurring() bool {
+	return e.RecurrenceRule != nil
+}
+
+// recurrenceSet builds the rule set for the event, starting at its date and
+// excluding its exception dates.
+func (e *Event) recurrenceSet() (*rrule.Set, error) {
+	option, err := rrule.StrToROption(strings.TrimSpace(*e.RecurrenceRule))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidRecurrenceRule, err)
+	}
+	if !option.Dtstart.IsZero() {
+		return nil, fmt.Errorf("%w: DTSTART is taken from the event date", ErrInvalidRecurrenceRule)
+	}
+	option.Dtstart = e.Date.UTC().Truncate(time.Second)
+
+	// Events repeat at most daily; finer rules would make every expansion
+	// walk through millions of occurrences
+	if option.Freq == rrule.HOURLY || option.Freq == rrule.MINUTELY || option.Freq == rrule.SECONDLY {
+		return nil, fmt.Errorf("%w: events can repeat at most daily", ErrInvalidRecurrenceRule)
+	}
+	if option.Count > MaxRecurrenceCount {
+		return nil, fmt.Errorf("%w: COUNT can be at most %d", ErrInvalidRecurrenceRule, MaxRecurrenceCount)
+	}
+	if !option.Until.IsZero() && option.Until.Sub(option.Dtstart) > MaxRecurrenceSpan {
+		return nil, fmt.Errorf("%w: UNTIL can be at most 10 years after the event date", ErrInvalidRecurrenceRule)
+	}
+
+	rule, err := rrule.NewRRule(*option)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidRecurrenceRule, err)
+	}
+
+	set := &rrule.Set{}
+	set.RRule(rule)
+	for _, exdate := range e.RecurrenceExDates {
+		set.ExDate(exdate.UTC())
+	}
+	return set, nil
+}
+
+// recurrenceEnd returns the start of the last occurrence, or nil when the
+// rule repeats forever.
+func (e *Event) recurrenceEnd() (*time.Time, error) {
+	if !e.IsRecurring() {
+		return nil, nil
+	}
+	set, err := e.recurrenceSet()
+	if err != nil {
+		return nil, err
+	}
+	options := set.GetRRule().OrigOptions
+	if options.Count == 0 && options.Until.IsZero() {
+		return nil, nil
+	}
+
+	var last *time.Time
+	next := set.Iterator()
+	for i := 0; ; i++ {
+		date, ok := next()
+		if !ok {
+			break
+		}
+		if i == MaxRecurrenceCount {
+			return nil, fmt.Errorf("%w: rule produces more than %d occurrences", ErrInvalidRecurrenceRule,
+				MaxRecurrenceCount)
+		}
+		last = &date
+	}
+	if last == nil {
+		return nil, fmt.Errorf("%w: rule produces no occurrences", ErrInvalidRecurrenceRule)
+	}
+	return last, nil
+}
+
+// occurrencesFrom returns an iterator over the occurrences of the set
+// starting at or after from. Rather than walk a rule that never ends
+// forever, it gives up after maxRecurrenceSteps occurrences.
+func occurrencesFrom(set *rrule.Set, from time.Time) func() (time.Time, bool) {
+	next := set.Iterator()
+	steps := 0
+	return func() (time.Time, bool) {
+		for steps < maxRecurrenceSteps {
+			steps++
+			date, ok := next()
+			if !ok {
+				return time.Time{}, false
+			}
+			if !date.Before(from) {
+				return date, true
+			}
+		}
+		return time.Time{}, false
+	}
+}
+
+// HasOccurrence reports whether date is an occurrence produced by the rule.
+func (e *Event) HasOccurrence(date time.Time) (bool, error) {
+	if !e.IsRecurring() {
+		return false, nil
+	}
+	set, err := e.recurrenceSet()
+	if err != nil {
+		return false, err
+	}
+	date = date.UTC()
+	first, ok := occurrencesFrom(set, date)()
+	return ok && first.Equal(date), nil
+}
+
+// ExpandOccurrences returns the occurrences starting in [from, to), with the
+// given overrides applied. At most MaxOccurrences are returned.
+func (e *Event) ExpandOccurrences(from, to time.Time, overrides []OccurrenceOverride) ([]Occurrence, error) {
+	set, err := e.recurrenceSet()
+	if err != nil {
+		return nil, err
+	}
+
+	byDate := map[time.Time]OccurrenceOverride{}
+	for _, o := range overrides {
+		byDate[o.OccurrenceDate.UTC()] = o
+	}
+
+	occurrences := []Occurrence{}
+	next := occurrencesFrom(set, from)
+	for len(occurrences) < MaxOccurrences {
+		date, ok := next()
+		if !ok || !date.Before(to) {
+			break
+		}
+
+		occurrence := Occurrence{
+			OriginalDate: date,
+			Date:         date,
+			Title:        e.Title,
+			Description:  e.Description,
+			Location:     e.Location,
+		}
+		if o, ok := byDate[date]; ok {
+			occurrence.Modified = true
+			if o.Title != nil {
+				occurrence.Title = *o.Title
+			}
+			if o.Description != nil {
+				occurrence.Description = *o.Description
+			}
+			if o.Location != nil {
+				occurrence.Location = *o.Location
+			}
+			if o.Date != nil {
+				occurrence.Date = o.Date.UTC()
+			}
+		}
+		occurrences = append(occurrences, occurrence)
+	}
+	return occurrences, nil
+}
+
+// ListOccurrences loads the event's overrides and expands its occurrences in
+// [from, to).
+func (e *Event) ListOccurrences(from, to time.Time) ([]Occurrence, error) {
+	overrides, err := GetOccurrenceOverrides([]int64{e.ID})
+	if err != nil {
+		return nil, err
+	}
+	return e.ExpandOccurrences(from, to, overrides[e.ID])
+}
+
+// SaveOccurrenceOverride creates or replaces the override for one occurrence
+// of the event.
+func (e *Event) SaveOccurrenceOverride(o *OccurrenceOverride) error {
+	ok, err := e.HasOccurrence(o.OccurrenceDate)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return ErrInvalidOccurrence
+	}
+
+	o.EventID = e.ID
+	o.OccurrenceDate = o.OccurrenceDate.UTC()
+	if o.Date != nil {
+		date := o.Date.UTC()
+		o.Date = &date
+	}
+
+	query := `
+    INSERT INTO event_occurrence_overrides (event_id, occurrence_date, title, description, location, date)
+    VALUES ($1, $2, $3, $4, $5, $6)
+    ON CONFLICT (event_id, occurrence_date) DO UPDATE
+    SET title = EXCLUDED.title,
+        description = EXCLUDED.description,
+        location = EXCLUDED.location,
+        date = EXCLUDED.date
+    RETURNING id
+    `
+	err = db.DB.QueryRow(query, o.EventID, o.OccurrenceDate, o.Title, o.Description, o.Location, o.Date).Scan(&o.ID)
+	if err != nil {
+		utils.Logger.Error("Failed to save occurrence override",
+			"event_id", e.ID,
+			"occurrence_date", o.OccurrenceDate,
+			"error", err)
+		return err
+	}
+	utils.Logger.Debug("Occurrence override saved", "event_id", e.ID, "occurrence_date", o.OccurrenceDate)
+	return nil
+}
+
+// CancelOccurrence adds the occurrence to the event's exception dates and
+// drops any override for it. Registrations for just that occurrence are kept
+// but marked as cancelled.
+func (e *Event) CancelOccurrence(date time.Time) error {
+	ok, err := e.HasOccurrence(date)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return ErrInvalidOccurrence
+	}
+	date = date.UTC()
+
+	tx, err := db.DB.Begin()
+	if err != nil {
+		utils.Logger.Error("Failed to begin occurrence cancellation", "event_id", e.ID, "error", err)
+		return err
+	}
+	defer tx.Rollback()
+
+	query := `
+    UPDATE events
+    SET recurrence_exdates = array_append(recurrence_exdates, $1),
+        version = version + 1, updated_at = NOW()
+    WHERE id = $2 AND deleted_at IS NULL
+    `
+	_, err = tx.Exec(query, date, e.ID)
+	if err != nil {
+		utils.Logger.Error("Failed to add exception date", "event_id", e.ID, "date", date, "error", err)
+		return err
+	}
+
+	_, err = tx.Exec("DELETE FROM event_occurrence_overrides WHERE event_id = $1 AND occurrence_date = $2", e.ID, date)
+	if err != nil {
+		utils.Logger.Error("Failed to delete occurrence override", "event_id", e.ID, "date", date, "error", err)
+		return err
+	}
+
+	query = `
+    UPDATE registrations SET status = $1
+    WHERE event_id = $2 AND occurrence_date = $3 AND status IN ($4, $5, $6)
+    `
+	_, err = tx.Exec(query, RegistrationCancelled, e.ID, date, RegistrationConfirmed, RegistrationWaitlisted,
+		RegistrationPending)
+	if err != nil {
+		utils.Logger.Error("Failed to cancel occurrence registrations", "event_id", e.ID, "date", date, "error", err)
+		return err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		utils.Logger.Error("Failed to commit occurrence cancellation", "event_id", e.ID, "error", err)
+		return err
+	}
+
+	e.RecurrenceExDates = append(e.RecurrenceExDates, date)
+	utils.Logger.Debug("Occurrence cancelled", "event_id", e.ID, "date", date)
+	return nil
+}
+
+// expandEventOccurrences fills in the occurrences of the recurring events in
+// the slice for the window [from, to).
+func expandEventOccurrences(events []Event, from, to time.Time) error {
+	ids := []int64{}
+	for _, e := range events {
+		if e.IsRecurring() {
+			ids = append(ids, e.ID)
+		}
+	}
+	if len(ids) == 0 {
+		return nil
+	}
+
+	overrides, err := GetOccurrenceOverrides(ids)
+	if err != nil {
+		return err
+	}
+
+	for i := range events {
+		if !events[i].IsRecurring() {
+			continue
+		}
+		occurrences, err := events[i].ExpandOccurrences(from, to, overrides[events[i].ID])
+		if err != nil {
+			utils.Logger.Warn("Failed to expand event occurrences", "event_id", events[i].ID, "error", err)
+			continue
+		}
+		events[i].Occurrences = occurrences
+	}
+	return nil
+}
+
+// GetOccurrenceOverrides returns the overrides of the given events, keyed by
+// event ID.
+func GetOccurrenceOverrides(eventIDs []int64) (map[int64][]OccurrenceOverride, error) {
+	query := `
+        SELECT id, event_id, occurrence_date, title, description, location, date
+        FROM event_occurrence_overrides
+        WHERE event_id = ANY($1)
+    `
+	rows, err := db.DB.Query(query, pq.Array(eventIDs))
+	if err != nil {
+		utils.Logger.Error("Failed to query occurrence overrides", "error", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	overrides := map[int64][]OccurrenceOverride{}
+	for rows.Next() {
+		var o OccurrenceOverride
+		var title, description, location sql.NullString
+		var date sql.NullTime
+		err := rows.Scan(&o.ID, &o.EventID, &o.OccurrenceDate, &title, &description, &location, &date)
+		if err != nil {
+			utils.Logger.Error("Failed to scan occurrence override row", "error", err)
+			return nil, err
+		}
+		if title.Valid {
+			o.Title = &title.String
+		}
+		if description.Valid {
+			o.Description = &description.String
+		}
+		if location.Valid {
+			o.Location = &location.String
+		}
+		if date.Valid {
+			o.Date = &date.Time
+		}
+		overrides[o.EventID] = append(overrides[o.EventID], o)
+	}
+	return overrides, nil
+}
+
+func formatExDates(dates []time.Time) []string {
+	formatted := make([]string, len(dates))
+	for i, d := range dates {
+		formatted[i] = d.UTC().Format(pgTimestampLayout)
+	}
+	return formatted
+}
+
+func parseExDates(values []string) ([]time.Time, error) {
+	dates := make([]time.Time, 0, len(values))
+	for _, v := range values {
+		d, err := time.ParseInLocation(pgTimestampLayout, v, time.UTC)
+		if err != nil {
+			return nil, err
+		}
+		dates = append(dates, d)
+	}
+	return dates, nil
+}
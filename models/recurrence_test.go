@@ -0,0 +1,104 @@
+package models
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestRecurrenceRuleLimits(t *testing.T) {
+	start := time.Date(2024, 1, 1, 18, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name    string
+		rule    string
+		wantErr bool
+		wantEnd *time.Time
+	}{
+		{name: "daily forever", rule: "FREQ=DAILY"},
+		{name: "weekly with count", rule: "FREQ=WEEKLY;COUNT=3", wantEnd: ptr(start.AddDate(0, 0, 14))},
+		{name: "count at limit", rule: "FREQ=DAILY;COUNT=1000", wantEnd: ptr(start.AddDate(0, 0, 999))},
+		{name: "until within span", rule: "FREQ=MONTHLY;UNTIL=20240401T180000Z", wantEnd: ptr(start.AddDate(0, 3, 0))},
+		{name: "hourly", rule: "FREQ=HOURLY", wantErr: true},
+		{name: "minutely", rule: "FREQ=MINUTELY;COUNT=10", wantErr: true},
+		{name: "secondly", rule: "FREQ=SECONDLY;COUNT=100000000", wantErr: true},
+		{name: "count over limit", rule: "FREQ=DAILY;COUNT=1001", wantErr: true},
+		{name: "until too far", rule: "FREQ=YEARLY;UNTIL=21000101T000000Z", wantErr: true},
+		{name: "until with too many occurrences", rule: "FREQ=DAILY;BYHOUR=1,2,3,4,5,6;UNTIL=20250101T000000Z", wantErr: true},
+		{name: "dtstart in rule", rule: "DTSTART:20240101T000000Z\nRRULE:FREQ=DAILY", wantErr: true},
+		{name: "garbage", rule: "FREQ=SOMETIMES", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := Event{Date: start, RecurrenceRule: &tt.rule}
+			end, err := e.recurrenceEnd()
+			if tt.wantErr {
+				if !errors.Is(err, ErrInvalidRecurrenceRule) {
+					t.Fatalf("error = %v, want ErrInvalidRecurrenceRule", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if (end == nil) != (tt.wantEnd == nil) || (end != nil && !end.Equal(*tt.wantEnd)) {
+				t.Errorf("end = %v, want %v", end, tt.wantEnd)
+			}
+		})
+	}
+}
+
+func TestExpandOccurrencesFromOldStart(t *testing.T) {
+	rule := "FREQ=DAILY"
+	e := Event{Date: time.Date(1900, 1, 1, 9, 0, 0, 0, time.UTC), RecurrenceRule: &rule}
+	from := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+
+	occurrences, err := e.ExpandOccurrences(from, from.AddDate(0, 0, 3), nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(occurrences) != 3 || !occurrences[0].Date.Equal(from.Add(9*time.Hour)) {
+		t.Errorf("occurrences = %+v, want 3 starting at %v", occurrences, from.Add(9*time.Hour))
+	}
+
+	// Past the step limit the walk gives up instead of running on
+	far := time.Date(2400, 1, 1, 0, 0, 0, 0, time.UTC)
+	occurrences, err = e.ExpandOccurrences(far, far.AddDate(0, 0, 3), nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(occurrences) != 0 {
+		t.Errorf("got %d occurrences beyond the step limit, want none", len(occurrences))
+	}
+}
+
+func TestHasOccurrence(t *testing.T) {
+	rule := "FREQ=WEEKLY;COUNT=4"
+	start := time.Date(2024, 1, 1, 18, 0, 0, 0, time.UTC)
+	e := Event{Date: start, RecurrenceRule: &rule, RecurrenceExDates: []time.Time{start.AddDate(0, 0, 7)}}
+
+	tests := []struct {
+		date time.Time
+		want bool
+	}{
+		{start, true},
+		{start.AddDate(0, 0, 7), false},
+		{start.AddDate(0, 0, 14), true},
+		{start.AddDate(0, 0, 14).Add(time.Hour), false},
+		{start.AddDate(0, 0, 28), false},
+		{start.AddDate(0, 0, -7), false},
+	}
+	for _, tt := range tests {
+		got, err := e.HasOccurrence(tt.date)
+		if err != nil {
+			t.Fatalf("HasOccurrence(%v) error: %v", tt.date, err)
+		}
+		if got != tt.want {
+			t.Errorf("HasOccurrence(%v) = %v, want %v", tt.date, got, tt.want)
+		}
+	}
+}
+
+func ptr[T any](v T) *T {
+	return &v
+}
@@ -1,20 +1,71 @@
 package models
 
-import "example.com/event-booking-api/db"
+import (
+	"database/sql"
+	"errors"
+	"time"
+
+	"example.com/event-booking-api/db"
+	"example.com/event-booking-api/utils"
+)
+
+const (
+	RegistrationConfirmed  = "confirmed"
+	RegistrationWaitlisted = "waitlisted"
+	// RegistrationPending and RegistrationRejected are only used by events
+	// that require approval. Neither holds a seat.
+	RegistrationPending  = "pending"
+	RegistrationRejected = "rejected"
+	// RegistrationCancelled marks registrations of a cancelled event or
+	// occurrence. They are kept for the record but hold no seat.
+	RegistrationCancelled = "cancelled"
+)
+
+var ErrRegistrationMissing = errors.New("registration does not exist")
+
+type Registration struct {
+	ID               int64      `json:"id"`
+	UserID           int64      `json:"user_id"`
+	EventID          int64      `json:"event_id"`
+	OccurrenceDate   *time.Time `json:"occurrence_date,omitempty"`
+	TicketTypeID     *int64     `json:"ticket_type_id,omitempty"`
+	PartySize        int        `json:"party_size"`
+	Guests           []Guest    `json:"guests,omitempty"`
+	SeatIDs          []int64    `json:"seat_ids,omitempty"`
+	Status           string     `json:"status"`
+	WaitlistPosition *int       `json:"waitlist_position,omitempty"`
+	RegisteredAt     time.Time  `json:"registered_at"`
+	PromoCode        string     `json:"promo_code,omitempty"`
+	DiscountCents    int        `json:"discount_cents,omitempty"`
+	// TicketCode is the signed code shown at the door. It is only handed out
+	// for confirmed registrations.
+	TicketCode string `json:"ticket_code,omitempty"`
+}
 
 type RegistrationWithUser struct {
-	ID      int64  `json:"id"`
-	UserID  int64  `json:"user_id"`
-	EventID int64  `json:"event_id"`
-	Email   string `json:"email"`
+	ID             int64      `json:"id"`
+	UserID         int64      `json:"user_id"`
+	EventID        int64      `json:"event_id"`
+	OccurrenceDate *time.Time `json:"occurrence_date,omitempty"`
+	TicketTypeID   *int64     `json:"ticket_type_id,omitempty"`
+	Email          string     `json:"email"`
+	Status         string     `json:"status"`
+	PartySize      int        `json:"party_size"`
+	Guests         []Guest    `json:"guests"`
+	Answers        []Answer   `json:"answers"`
+	// CheckedInAt is the latest check-in of the registration, if any.
+	CheckedInAt *time.Time `json:"checked_in_at,omitempty"`
 }
 
 func GetRegistrationsByEventIDWithUsers(eventID int64) ([]RegistrationWithUser, error) {
 	query := `
-        SELECT r.id, r.user_id, r.event_id, u.email 
+        SELECT r.id, r.user_id, r.event_id, r.occurrence_date, r.ticket_type_id, u.email, r.status,
+            r.party_size,
+            (SELECT MAX(checked_in_at) FROM check_ins WHERE registration_id = r.id)
         FROM registrations r
         JOIN users u ON r.user_id = u.id
-        WHERE r.event_id = $1
+        WHERE r.event_id = $1 AND u.deleted_at IS NULL
+        ORDER BY r.id
     `
 	rows, err := db.DB.Query(query, eventID)
 	if err != nil {
@@ -25,12 +76,306 @@ func GetRegistrationsByEventIDWithUsers(eventID int64) ([]RegistrationWithUser,
 	registrations := []RegistrationWithUser{}
 	for rows.Next() {
 		var r RegistrationWithUser
-		err := rows.Scan(&r.ID, &r.UserID, &r.EventID, &r.Email)
+		var occurrence sql.NullTime
+		var ticketTypeID sql.NullInt64
+		var checkedInAt sql.NullTime
+		err := rows.Scan(&r.ID, &r.UserID, &r.EventID, &occurrence, &ticketTypeID, &r.Email, &r.Status,
+			&r.PartySize, &checkedInAt)
 		if err != nil {
 			return nil, err
 		}
+		if occurrence.Valid {
+			r.OccurrenceDate = &occurrence.Time
+		}
+		if ticketTypeID.Valid {
+			r.TicketTypeID = &ticketTypeID.Int64
+		}
+		if checkedInAt.Valid {
+			r.CheckedInAt = &checkedInAt.Time
+		}
 		registrations = append(registrations, r)
 	}
 
+	ids := make([]int64, len(registrations))
+	for i, r := range registrations {
+		ids[i] = r.ID
+	}
+	guests, err := getGuests(ids)
+	if err != nil {
+		return nil, err
+	}
+	for i := range registrations {
+		registrations[i].Guests = guests[registrations[i].ID]
+		if registrations[i].Guests == nil {
+			registrations[i].Guests = []Guest{}
+		}
+	}
+
+	answers, err := getAnswers(ids)
+	if err != nil {
+		return nil, err
+	}
+	for i := range registrations {
+		registrations[i].Answers = answers[registrations[i].ID]
+		if registrations[i].Answers == nil {
+			registrations[i].Answers = []Answer{}
+		}
+	}
+
 	return registrations, nil
 }
+
+func GetRegistrationByID(id int64) (*Registration, error) {
+	query := `
+        SELECT r.id, r.user_id, r.event_id, r.occurrence_date, r.ticket_type_id, r.party_size, r.status,
+            r.registered_at
+        FROM registrations r
+        JOIN events e ON e.id = r.event_id
+        WHERE r.id = $1 AND e.deleted_at IS NULL
+    `
+	var r Registration
+	var occurrence sql.NullTime
+	var ticketTypeID sql.NullInt64
+	err := db.DB.QueryRow(query, id).Scan(&r.ID, &r.UserID, &r.EventID, &occurrence, &ticketTypeID,
+		&r.PartySize, &r.Status, &r.RegisteredAt)
+	if err == sql.ErrNoRows {
+		return nil, ErrRegistrationMissing
+	}
+	if err != nil {
+		utils.Logger.Error("Failed to get registration by ID", "registration_id", id, "error", err)
+		return nil, err
+	}
+	if occurrence.Valid {
+		r.OccurrenceDate = &occurrence.Time
+	}
+	if ticketTypeID.Valid {
+		r.TicketTypeID = &ticketTypeID.Int64
+	}
+	return &r, nil
+}
+
+// RegisteredEvent is an event a user holds a confirmed seat in. When the
+// registration covers a single occurrence, OccurrenceDate is set.
+type RegisteredEvent struct {
+	Event          Event
+	OccurrenceDate *time.Time
+}
+
+func GetConfirmedEventsForUser(userID int64) ([]RegisteredEvent, error) {
+	query := `
+        SELECT ` + eventColumns + `, r.occurrence_date
+        FROM events
+        JOIN (
+            SELECT event_id, occurrence_date FROM registrations
+            WHERE user_id = $1 AND status = $2
+        ) r ON r.event_id = events.id
+        WHERE deleted_at IS NULL
+        ORDER BY date, id
+    `
+	rows, err := db.DB.Query(query, userID, RegistrationConfirmed)
+	if err != nil {
+		utils.Logger.Error("Failed to query registered events", "user_id", userID, "error", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	registered := []RegisteredEvent{}
+	for rows.Next() {
+		var occurrence sql.NullTime
+		e, err := scanEvent(rows, &occurrence)
+		if err != nil {
+			utils.Logger.Error("Failed to scan registered event row", "error", err)
+			return nil, err
+		}
+		r := RegisteredEvent{Event: *e}
+		if occurrence.Valid {
+			r.OccurrenceDate = &occurrence.Time
+		}
+		registered = append(registered, r)
+	}
+
+	utils.Logger.Debug("Retrieved registered events", "user_id", userID, "count", len(registered))
+	return registered, nil
+}
+
+// eventLock holds the event fields read while locking it.
+type eventLock struct {
+	capacity         sql.NullInt64
+	status           string
+	requiresApproval bool
+}
+
+// lockEvent takes a row lock on the event for the rest of the transaction,
+// serialising registrations for it.
+func lockEvent(tx *sql.Tx, eventID int64) (*eventLock, error) {
+	var locked eventLock
+	query := "SELECT capacity, status, requires_approval FROM events WHERE id = $1 AND deleted_at IS NULL FOR UPDATE"
+	err := tx.QueryRow(query, eventID).Scan(&locked.capacity, &locked.status, &locked.requiresApproval)
+	if err != nil {
+		utils.Logger.Error("Failed to lock event", "event_id", eventID, "error", err)
+		return nil, err
+	}
+	return &locked, nil
+}
+
+// seatsTaken counts the confirmed seats for one occurrence of the event, or
+// for the whole event when occurrence is nil. Each registration takes a seat
+// for the registrant and one per guest. Series registrations hold their
+// seats in every occurrence, so for the whole event the busiest occurrence is
+// what counts. When ticketTypeID is set only seats of that type are counted.
+func seatsTaken(tx *sql.Tx, eventID int64, occurrence *time.Time, ticketTypeID *int64) (int64, error) {
+	query := `
+        SELECT
+            (SELECT COALESCE(SUM(party_size), 0) FROM registrations
+             WHERE event_id = $1 AND status = $2 AND occurrence_date IS NULL
+               AND ($4::integer IS NULL OR ticket_type_id = $4))
+            +
+            CASE WHEN $3::timestamp IS NULL THEN
+                (SELECT COALESCE(MAX(seats), 0) FROM (
+                    SELECT SUM(party_size) AS seats FROM registrations
+                    WHERE event_id = $1 AND status = $2 AND occurrence_date IS NOT NULL
+                      AND ($4::integer IS NULL OR ticket_type_id = $4)
+                    GROUP BY occurrence_date
+                ) per_occurrence)
+            ELSE
+                (SELECT COALESCE(SUM(party_size), 0) FROM registrations
+                 WHERE event_id = $1 AND status = $2 AND occurrence_date = $3
+                   AND ($4::integer IS NULL OR ticket_type_id = $4))
+            END
+    `
+	var count int64
+	err := tx.QueryRow(query, eventID, RegistrationConfirmed, occurrence, ticketTypeID).Scan(&count)
+	if err != nil {
+		utils.Logger.Error("Failed to count registrations", "event_id", eventID, "error", err)
+	}
+	return count, err
+}
+
+// hasFreeSeats reports whether the given number of seats fit under limit,
+// counting only seats of the ticket type when ticketTypeID is set. A null
+// limit means unlimited.
+func hasFreeSeats(tx *sql.Tx, eventID int64, limit sql.NullInt64, occurrence *time.Time, ticketTypeID *int64, seats int) (bool, error) {
+	if !limit.Valid {
+		return true, nil
+	}
+	taken, err := seatsTaken(tx, eventID, occurrence, ticketTypeID)
+	if err != nil {
+		return false, err
+	}
+	return taken+int64(seats) <= limit.Int64, nil
+}
+
+// fitsSeats reports whether a party of the given size has room both in the
+// event and, when ticketTypeID is set, in the ticket type's quantity.
+func fitsSeats(tx *sql.Tx, eventID int64, capacity, quantity sql.NullInt64, occurrence *time.Time, ticketTypeID *int64, seats int) (bool, error) {
+	available, err := hasFreeSeats(tx, eventID, capacity, occurrence, nil, seats)
+	if err != nil || !available || ticketTypeID == nil {
+		return available, err
+	}
+	return hasFreeSeats(tx, eventID, quantity, occurrence, ticketTypeID, seats)
+}
+
+func hasSeriesRegistration(tx *sql.Tx, eventID, userID int64) (bool, error) {
+	query := `
+        SELECT EXISTS (
+            SELECT 1 FROM registrations
+            WHERE event_id = $1 AND user_id = $2 AND occurrence_date IS NULL
+        )
+    `
+	var exists bool
+	err := tx.QueryRow(query, eventID, userID).Scan(&exists)
+	if err != nil {
+		utils.Logger.Error("Failed to check series registration", "event_id", eventID, "user_id", userID, "error", err)
+	}
+	return exists, err
+}
+
+func waitlistPosition(tx *sql.Tx, eventID int64, occurrence *time.Time, registrationID int64) (int, error) {
+	query := `
+        SELECT COUNT(*) FROM registrations
+        WHERE event_id = $1 AND status = $2 AND occurrence_date IS NOT DISTINCT FROM $3 AND id <= $4
+    `
+	var position int
+	err := tx.QueryRow(query, eventID, RegistrationWaitlisted, occurrence, registrationID).Scan(&position)
+	if err != nil {
+		utils.Logger.Error("Failed to get waitlist position", "event_id", eventID, "error", err)
+	}
+	return position, err
+}
+
+// promoteWaitlisted moves users from the waitlist into free seats, in the
+// order they joined it. Parties too large for the seats left are passed over
+// for smaller ones behind them, as are deleted users. It takes the event
+// lock itself, so callers that already hold it can call it safely.
+func promoteWaitlisted(tx *sql.Tx, eventID int64) ([]int64, error) {
+	locked, err := lockEvent(tx, eventID)
+	if err != nil {
+		return nil, err
+	}
+	if locked.status == EventCancelled {
+		return nil, nil
+	}
+
+	type waitlisted struct {
+		id, userID   int64
+		occurrence   *time.Time
+		ticketTypeID *int64
+		quantity     sql.NullInt64
+		partySize    int
+	}
+
+	query := `
+        SELECT r.id, r.user_id, r.occurrence_date, r.ticket_type_id, t.quantity, r.party_size
+        FROM registrations r
+        LEFT JOIN ticket_types t ON t.id = r.ticket_type_id
+        WHERE r.event_id = $1 AND r.status = $2
+          AND r.user_id IN (SELECT id FROM users WHERE deleted_at IS NULL)
+        ORDER BY r.id
+    `
+	rows, err := tx.Query(query, eventID, RegistrationWaitlisted)
+	if err != nil {
+		utils.Logger.Error("Failed to query waitlist", "event_id", eventID, "error", err)
+		return nil, err
+	}
+	queue := []waitlisted{}
+	for rows.Next() {
+		var w waitlisted
+		var occurrence sql.NullTime
+		var ticketTypeID sql.NullInt64
+		err := rows.Scan(&w.id, &w.userID, &occurrence, &ticketTypeID, &w.quantity, &w.partySize)
+		if err != nil {
+			rows.Close()
+			return nil, err
+		}
+		if occurrence.Valid {
+			w.occurrence = &occurrence.Time
+		}
+		if ticketTypeID.Valid {
+			w.ticketTypeID = &ticketTypeID.Int64
+		}
+		queue = append(queue, w)
+	}
+	rows.Close()
+
+	promoted := []int64{}
+	for _, w := range queue {
+		available, err := fitsSeats(tx, eventID, locked.capacity, w.quantity, w.occurrence, w.ticketTypeID, w.partySize)
+		if err != nil {
+			return nil, err
+		}
+		if !available {
+			continue
+		}
+		_, err = tx.Exec("UPDATE registrations SET status = $1 WHERE id = $2", RegistrationConfirmed, w.id)
+		if err != nil {
+			utils.Logger.Error("Failed to promote waitlisted user", "event_id", eventID, "registration_id", w.id, "error", err)
+			return nil, err
+		}
+		promoted = append(promoted, w.userID)
+	}
+
+	if len(promoted) > 0 {
+		utils.Logger.Info("Promoted users from waitlist", "event_id", eventID, "user_ids", promoted)
+	}
+	return promoted, nil
+}
@@ -0,0 +1,124 @@
+package models
+
+import (
+	"database/sql"
+	"encoding/json"
+	"strconv"
+	"strings"
+	"time"
+
+	"example.com/event-booking-api/db"
+	"example.com/event-booking-api/utils"
+)
+
+// ExportedRegistration is one row of a registrations export.
+type ExportedRegistration struct {
+	ID             int64
+	UserID         int64
+	Email          string
+	OccurrenceDate *time.Time
+	TicketType     *string
+	PartySize      int
+	Guests         []string
+	Status         string
+	RegisteredAt   time.Time
+	// CheckedInAt is the latest check-in of the registration, if any.
+	CheckedInAt *time.Time
+	// Answers are keyed by question ID.
+	Answers map[int64]json.RawMessage
+}
+
+// ExportRegistrations calls fn for each registration of the event, oldest
+// first. Rows are read from the database as fn consumes them, so exporting
+// a large event does not load all of its registrations at once. Guests and
+// answers are aggregated per row by the query itself.
+func ExportRegistrations(eventID int64, fn func(*ExportedRegistration) error) error {
+	query := `
+        SELECT r.id, r.user_id, u.email, r.occurrence_date, t.name, r.party_size, r.status, r.registered_at,
+            (SELECT MAX(checked_in_at) FROM check_ins WHERE registration_id = r.id),
+            (SELECT COALESCE(json_agg(name ORDER BY id), '[]') FROM registration_guests WHERE registration_id = r.id),
+            (SELECT COALESCE(jsonb_object_agg(question_id, value), '{}') FROM registration_answers WHERE registration_id = r.id)
+        FROM registrations r
+        JOIN users u ON r.user_id = u.id
+        LEFT JOIN ticket_types t ON t.id = r.ticket_type_id
+        WHERE r.event_id = $1 AND u.deleted_at IS NULL
+        ORDER BY r.id
+    `
+	rows, err := db.DB.Query(query, eventID)
+	if err != nil {
+		utils.Logger.Error("Failed to query registrations for export", "event_id", eventID, "error", err)
+		return err
+	}
+	defer rows.Close()
+
+	count := 0
+	for rows.Next() {
+		var r ExportedRegistration
+		var occurrence, checkedInAt sql.NullTime
+		var ticketType sql.NullString
+		var guests, answers []byte
+		err := rows.Scan(&r.ID, &r.UserID, &r.Email, &occurrence, &ticketType, &r.PartySize, &r.Status,
+			&r.RegisteredAt, &checkedInAt, &guests, &answers)
+		if err != nil {
+			utils.Logger.Error("Failed to scan exported registration row", "event_id", eventID, "error", err)
+			return err
+		}
+		if occurrence.Valid {
+			r.OccurrenceDate = &occurrence.Time
+		}
+		if ticketType.Valid {
+			r.TicketType = &ticketType.String
+		}
+		if checkedInAt.Valid {
+			r.CheckedInAt = &checkedInAt.Time
+		}
+		err = json.Unmarshal(guests, &r.Guests)
+		if err != nil {
+			return err
+		}
+		err = json.Unmarshal(answers, &r.Answers)
+		if err != nil {
+			return err
+		}
+
+		err = fn(&r)
+		if err != nil {
+			return err
+		}
+		count++
+	}
+	err = rows.Err()
+	if err != nil {
+		utils.Logger.Error("Failed to read registrations for export", "event_id", eventID, "error", err)
+		return err
+	}
+
+	utils.Logger.Debug("Exported registrations", "event_id", eventID, "count", count)
+	return nil
+}
+
+// AnswerCell renders a stored answer for a spreadsheet: numbers stay
+// numbers, multiple choices are joined with "; " and missing answers are nil.
+func (q *Question) AnswerCell(raw json.RawMessage) any {
+	if len(raw) == 0 || string(raw) == "null" {
+		return nil
+	}
+	switch q.Type {
+	case QuestionNumber:
+		number, err := strconv.ParseFloat(string(raw), 64)
+		if err == nil {
+			return number
+		}
+	case QuestionMultiChoice:
+		var choices []string
+		if json.Unmarshal(raw, &choices) == nil {
+			return strings.Join(choices, "; ")
+		}
+	default:
+		var text string
+		if json.Unmarshal(raw, &text) == nil {
+			return text
+		}
+	}
+	return string(raw)
+}
@@ -0,0 +1,64 @@
+package models
+
+import (
+	"errors"
+	"time"
+)
+
+var (
+	ErrRegistrationNotOpen       = errors.New("registration has not opened yet")
+	ErrRegistrationClosed        = errors.New("registration has closed")
+	ErrUnregistrationClosed      = errors.New("the deadline for unregistering has passed")
+	ErrInvalidRegistrationWindow = errors.New("registration must open before it closes")
+)
+
+func (e *Event) validateRegistrationWindow() error {
+	if e.RegistrationOpensAt != nil && e.RegistrationClosesAt != nil &&
+		!e.RegistrationOpensAt.Before(*e.RegistrationClosesAt) {
+		return ErrInvalidRegistrationWindow
+	}
+	return nil
+}
+
+// registrationStart is when what a registration covers begins: the given
+// occurrence, or the event itself when it does not recur. Series
+// registrations of recurring events have no single start.
+func (e *Event) registrationStart(occurrence *time.Time) *time.Time {
+	if occurrence != nil {
+		return occurrence
+	}
+	if !e.IsRecurring() {
+		return &e.Date
+	}
+	return nil
+}
+
+// RegistrationClosesAtFor returns when registration for the event, or for
+// one occurrence of it, closes. Unless the organizer set a closing time it
+// closes when the event starts. Nil means it does not close.
+func (e *Event) RegistrationClosesAtFor(occurrence *time.Time) *time.Time {
+	if e.RegistrationClosesAt != nil {
+		return e.RegistrationClosesAt
+	}
+	return e.registrationStart(occurrence)
+}
+
+// UnregistrationClosesAtFor returns the deadline for giving up a confirmed
+// seat. It defaults to the start of the event or occurrence.
+func (e *Event) UnregistrationClosesAtFor(occurrence *time.Time) *time.Time {
+	if e.UnregistrationClosesAt != nil {
+		return e.UnregistrationClosesAt
+	}
+	return e.registrationStart(occurrence)
+}
+
+func (e *Event) checkRegistrationWindow(occurrence *time.Time, now time.Time) error {
+	if e.RegistrationOpensAt != nil && now.Before(*e.RegistrationOpensAt) {
+		return ErrRegistrationNotOpen
+	}
+	closes := e.RegistrationClosesAtFor(occurrence)
+	if closes != nil && !now.Before(*closes) {
+		return ErrRegistrationClosed
+	}
+	return nil
+}
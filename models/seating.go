@@ -0,0 +1,342 @@
+package models
+
+import (
+	"database/sql"
+	"errors"
+	"slices"
+	"time"
+
+	"example.com/event-booking-api/db"
+	"example.com/event-booking-api/utils"
+	"github.com/lib/pq"
+)
+
+const (
+	SeatAvailable = "available"
+	SeatHeld      = "held"
+	SeatBooked    = "booked"
+)
+
+// SeatHoldDuration is how long held seats are kept for their holder before
+// anyone else may take them.
+var SeatHoldDuration = 10 * time.Minute
+
+var (
+	ErrNotSeated    = errors.New("event has no reserved seating")
+	ErrUnknownSeat  = errors.New("seat does not belong to the event's venue")
+	ErrSeatTaken    = errors.New("seat is already held or booked")
+	ErrSeatsNotHeld = errors.New("held seats do not match the party size")
+	ErrSoldOut      = errors.New("no seats left for this registration")
+	ErrVenueLocked  = errors.New("venue cannot change once seats are held or booked")
+
+	ErrSeatedApproval = errors.New("events with reserved seating cannot require approval")
+)
+
+// SeatAvailability is the state of one seat for an event, or for one
+// occurrence of it.
+type SeatAvailability struct {
+	SeatID    int64  `json:"seat_id"`
+	Section   string `json:"section"`
+	Row       string `json:"row"`
+	Label     string `json:"label"`
+	Status    string `json:"status"`
+	HeldByYou bool   `json:"held_by_you,omitempty"`
+}
+
+// SeatHold is a set of seats held for a user until ExpiresAt.
+type SeatHold struct {
+	EventID        int64      `json:"event_id"`
+	OccurrenceDate *time.Time `json:"occurrence_date,omitempty"`
+	SeatIDs        []int64    `json:"seat_ids"`
+	ExpiresAt      time.Time  `json:"expires_at"`
+}
+
+func (e *Event) IsSeated() bool {
+	return e.VenueID != nil
+}
+
+// validateSeating rejects seated events that require approval. Seats are
+// taken when registering, so a pending applicant would keep them from
+// everyone else until reviewed, while capacity is meant to count approved
+// registrations only.
+func (e *Event) validateSeating() error {
+	if e.IsSeated() && e.RequiresApproval {
+		return ErrSeatedApproval
+	}
+	return nil
+}
+
+// checkVenueChange returns ErrVenueLocked if the event is moving to another
+// venue, or dropping its venue, while seats of the current one are held or
+// booked. Expired holds do not count and are cleared.
+func checkVenueChange(tx *sql.Tx, eventID int64, venueID *int64) error {
+	var current sql.NullInt64
+	err := tx.QueryRow("SELECT venue_id FROM events WHERE id = $1 FOR UPDATE", eventID).Scan(&current)
+	if err == sql.ErrNoRows {
+		// Update reports the missing event as a version conflict
+		return nil
+	}
+	if err != nil {
+		utils.Logger.Error("Failed to lock event for venue change", "event_id", eventID, "error", err)
+		return err
+	}
+	if !current.Valid || (venueID != nil && *venueID == current.Int64) {
+		return nil
+	}
+
+	query := `
+    DELETE FROM event_seats
+    WHERE event_id = $1 AND registration_id IS NULL AND held_until <= $2
+    `
+	_, err = tx.Exec(query, eventID, time.Now().UTC())
+	if err != nil {
+		utils.Logger.Error("Failed to clear expired seat holds", "event_id", eventID, "error", err)
+		return err
+	}
+
+	var taken bool
+	err = tx.QueryRow("SELECT EXISTS (SELECT 1 FROM event_seats WHERE event_id = $1)", eventID).Scan(&taken)
+	if err != nil {
+		utils.Logger.Error("Failed to check event seats", "event_id", eventID, "error", err)
+		return err
+	}
+	if taken {
+		return ErrVenueLocked
+	}
+	return nil
+}
+
+// resolveSeatOccurrence is resolveOccurrence for seated events. Seats are
+// sold per occurrence, so recurring seated events need one.
+func (e *Event) resolveSeatOccurrence(date *time.Time) (*time.Time, error) {
+	if e.IsRecurring() && date == nil {
+		return nil, ErrOccurrenceRequired
+	}
+	return e.resolveOccurrence(date)
+}
+
+// GetSeatAvailability lists every seat of the event's venue in layout order
+// with its state. Seats held by userID are flagged as such.
+func (e *Event) GetSeatAvailability(occurrenceDate *time.Time, userID int64) ([]SeatAvailability, error) {
+	if !e.IsSeated() {
+		return nil, ErrNotSeated
+	}
+	occurrence, err := e.resolveSeatOccurrence(occurrenceDate)
+	if err != nil {
+		return nil, err
+	}
+
+	query := `
+        SELECT s.id, sec.name, r.label, s.label, es.registration_id IS NOT NULL, es.user_id
+        FROM venue_sections sec
+        JOIN venue_rows r ON r.section_id = sec.id
+        JOIN venue_seats s ON s.row_id = r.id
+        LEFT JOIN event_seats es ON es.seat_id = s.id AND es.event_id = $2
+            AND es.occurrence_date IS NOT DISTINCT FROM $3
+            AND (es.held_until IS NULL OR es.held_until > $4)
+        WHERE sec.venue_id = $1
+        ORDER BY sec.position, r.position, s.position
+    `
+	rows, err := db.DB.Query(query, *e.VenueID, e.ID, occurrence, time.Now().UTC())
+	if err != nil {
+		utils.Logger.Error("Failed to query seat availability", "event_id", e.ID, "error", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	seats := []SeatAvailability{}
+	for rows.Next() {
+		var s SeatAvailability
+		var booked bool
+		var holder sql.NullInt64
+		err := rows.Scan(&s.SeatID, &s.Section, &s.Row, &s.Label, &booked, &holder)
+		if err != nil {
+			utils.Logger.Error("Failed to scan seat availability row", "event_id", e.ID, "error", err)
+			return nil, err
+		}
+		switch {
+		case booked:
+			s.Status = SeatBooked
+		case holder.Valid:
+			s.Status = SeatHeld
+			s.HeldByYou = holder.Int64 == userID
+		default:
+			s.Status = SeatAvailable
+		}
+		seats = append(seats, s)
+	}
+	return seats, nil
+}
+
+// HoldSeats holds the given seats for the user for SeatHoldDuration,
+// replacing any seats they held for the same event or occurrence before.
+// The unique constraint on event_seats decides between concurrent requests
+// for the same seat: the loser gets ErrSeatTaken.
+func (e *Event) HoldSeats(userID int64, seatIDs []int64, occurrenceDate *time.Time) (*SeatHold, error) {
+	if e.Status == EventCompleted {
+		return nil, ErrRegistrationClosed
+	}
+	if e.Status != EventPublished {
+		return nil, ErrEventNotOpen
+	}
+	if !e.IsSeated() {
+		return nil, ErrNotSeated
+	}
+	occurrence, err := e.resolveSeatOccurrence(occurrenceDate)
+	if err != nil {
+		return nil, err
+	}
+	err = e.checkRegistrationWindow(occurrence, time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	seatIDs = slices.Clone(seatIDs)
+	slices.Sort(seatIDs)
+	seatIDs = slices.Compact(seatIDs)
+	now := time.Now().UTC()
+	hold := &SeatHold{
+		EventID:        e.ID,
+		OccurrenceDate: occurrence,
+		SeatIDs:        seatIDs,
+		ExpiresAt:      now.Add(SeatHoldDuration),
+	}
+
+	tx, err := db.DB.Begin()
+	if err != nil {
+		utils.Logger.Error("Failed to begin seat hold transaction", "event_id", e.ID, "error", err)
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	query := `
+        SELECT COUNT(*) FROM venue_seats s
+        JOIN venue_rows r ON r.id = s.row_id
+        JOIN venue_sections sec ON sec.id = r.section_id
+        WHERE sec.venue_id = $1 AND s.id = ANY($2)
+    `
+	var found int
+	err = tx.QueryRow(query, *e.VenueID, pq.Array(seatIDs)).Scan(&found)
+	if err != nil {
+		utils.Logger.Error("Failed to check held seats", "event_id", e.ID, "error", err)
+		return nil, err
+	}
+	if found != len(seatIDs) {
+		return nil, ErrUnknownSeat
+	}
+
+	err = releaseHeldSeats(tx, e.ID, userID, occurrence)
+	if err != nil {
+		return nil, err
+	}
+
+	// Expired holds no longer count, but the rows still occupy the unique
+	// constraint until they are cleared
+	query = `
+    DELETE FROM event_seats
+    WHERE event_id = $1 AND occurrence_date IS NOT DISTINCT FROM $2 AND seat_id = ANY($3)
+      AND registration_id IS NULL AND held_until <= $4
+    `
+	_, err = tx.Exec(query, e.ID, occurrence, pq.Array(seatIDs), now)
+	if err != nil {
+		utils.Logger.Error("Failed to clear expired seat holds", "event_id", e.ID, "error", err)
+		return nil, err
+	}
+
+	query = `
+    INSERT INTO event_seats (event_id, seat_id, occurrence_date, user_id, held_until)
+    SELECT $1, unnest($2::integer[]), $3, $4, $5
+    `
+	_, err = tx.Exec(query, e.ID, pq.Array(seatIDs), occurrence, userID, hold.ExpiresAt)
+	if isUniqueViolation(err) {
+		return nil, ErrSeatTaken
+	}
+	if err != nil {
+		utils.Logger.Error("Failed to hold seats", "event_id", e.ID, "user_id", userID, "error", err)
+		return nil, err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		utils.Logger.Error("Failed to commit seat hold", "event_id", e.ID, "user_id", userID, "error", err)
+		return nil, err
+	}
+
+	utils.Logger.Debug("Seats held", "event_id", e.ID, "user_id", userID, "seats", len(seatIDs))
+	return hold, nil
+}
+
+// ReleaseSeats gives up the seats the user holds for the event or
+// occurrence. Booked seats are only released by unregistering.
+func (e *Event) ReleaseSeats(userID int64, occurrenceDate *time.Time) error {
+	var occurrence *time.Time
+	if occurrenceDate != nil {
+		date := occurrenceDate.UTC()
+		occurrence = &date
+	}
+
+	tx, err := db.DB.Begin()
+	if err != nil {
+		utils.Logger.Error("Failed to begin seat release transaction", "event_id", e.ID, "error", err)
+		return err
+	}
+	defer tx.Rollback()
+
+	err = releaseHeldSeats(tx, e.ID, userID, occurrence)
+	if err != nil {
+		return err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		utils.Logger.Error("Failed to commit seat release", "event_id", e.ID, "user_id", userID, "error", err)
+		return err
+	}
+	utils.Logger.Debug("Seats released", "event_id", e.ID, "user_id", userID)
+	return nil
+}
+
+func releaseHeldSeats(tx *sql.Tx, eventID, userID int64, occurrence *time.Time) error {
+	query := `
+    DELETE FROM event_seats
+    WHERE event_id = $1 AND user_id = $2 AND occurrence_date IS NOT DISTINCT FROM $3 AND registration_id IS NULL
+    `
+	_, err := tx.Exec(query, eventID, userID, occurrence)
+	if err != nil {
+		utils.Logger.Error("Failed to release held seats", "event_id", eventID, "user_id", userID, "error", err)
+	}
+	return err
+}
+
+// bookHeldSeats turns the seats the registrant holds into seats booked by
+// the registration. Exactly one live hold per member of the party is needed.
+func bookHeldSeats(tx *sql.Tx, r *Registration) error {
+	query := `
+    UPDATE event_seats SET registration_id = $1, held_until = NULL
+    WHERE event_id = $2 AND user_id = $3 AND occurrence_date IS NOT DISTINCT FROM $4
+      AND registration_id IS NULL AND held_until > $5
+    RETURNING seat_id
+    `
+	rows, err := tx.Query(query, r.ID, r.EventID, r.UserID, r.OccurrenceDate, time.Now().UTC())
+	if err != nil {
+		utils.Logger.Error("Failed to book held seats", "registration_id", r.ID, "error", err)
+		return err
+	}
+	defer rows.Close()
+
+	r.SeatIDs = []int64{}
+	for rows.Next() {
+		var seatID int64
+		err := rows.Scan(&seatID)
+		if err != nil {
+			utils.Logger.Error("Failed to scan booked seat", "registration_id", r.ID, "error", err)
+			return err
+		}
+		r.SeatIDs = append(r.SeatIDs, seatID)
+	}
+	if len(r.SeatIDs) != r.PartySize {
+		return ErrSeatsNotHeld
+	}
+	slices.Sort(r.SeatIDs)
+	return nil
+}
@@ -0,0 +1,73 @@
+package models
+
+import (
+	"database/sql"
+	"errors"
+
+	"example.com/event-booking-api/db"
+	"example.com/event-booking-api/utils"
+)
+
+var ErrUnknownServiceAccount = errors.New("service account does not exist")
+
+// CreateServiceAccount adds a service account with the given role. Its
+// address counts as verified and it has no usable password.
+func CreateServiceAccount(email, role string) (*User, error) {
+	u := User{Email: email, Role: role, EmailVerified: true, ServiceAccount: true}
+	query := `
+    INSERT INTO users (email, password, role, service_account, email_verified_at)
+    VALUES ($1, '', $2, TRUE, NOW())
+    RETURNING id, version, token_version
+    `
+	err := db.DB.QueryRow(query, email, role).Scan(&u.ID, &u.Version, &u.TokenVersion)
+	if isUniqueViolation(err) {
+		return nil, ErrEmailTaken
+	}
+	if isForeignKeyViolation(err) {
+		return nil, ErrUnknownRole
+	}
+	if err != nil {
+		utils.Logger.Error("Failed to save service account to database", "email", email, "error", err)
+		return nil, err
+	}
+	utils.Logger.Debug("Service account saved to database", "user_id", u.ID, "email", email)
+	return &u, nil
+}
+
+func GetServiceAccounts() ([]User, error) {
+	query := "SELECT " + userColumns + " FROM users WHERE service_account AND deleted_at IS NULL ORDER BY id"
+	rows, err := db.DB.Query(query)
+	if err != nil {
+		utils.Logger.Error("Failed to query service accounts", "error", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	accounts := []User{}
+	for rows.Next() {
+		u, err := scanUser(rows)
+		if err != nil {
+			utils.Logger.Error("Failed to scan service account row", "error", err)
+			return nil, err
+		}
+		accounts = append(accounts, *u)
+	}
+
+	utils.Logger.Debug("Retrieved service accounts", "count", len(accounts))
+	return accounts, nil
+}
+
+// GetServiceAccount loads a service account. It returns ErrUnknownServiceAccount if
+// there is no service account with this ID.
+func GetServiceAccount(id int64) (*User, error) {
+	query := "SELECT " + userColumns + " FROM users WHERE id = $1 AND service_account AND deleted_at IS NULL"
+	u, err := scanUser(db.DB.QueryRow(query, id))
+	if err == sql.ErrNoRows {
+		return nil, ErrUnknownServiceAccount
+	}
+	if err != nil {
+		utils.Logger.Error("Failed to get service account", "user_id", id, "error", err)
+		return nil, err
+	}
+	return u, nil
+}
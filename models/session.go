@@ -0,0 +1,232 @@
+package models
+
+import (
+	"database/sql"
+	"errors"
+	"time"
+
+	"example.com/event-booking-api/db"
+	"example.com/event-booking-api/utils"
+)
+
+var (
+	ErrInvalidRefreshToken = errors.New("refresh token is invalid, expired or revoked")
+	ErrRefreshTokenReused  = errors.New("refresh token was already used")
+)
+
+// RefreshTokenDuration is how long a refresh token can be exchanged for a
+// new pair of tokens.
+var RefreshTokenDuration = 30 * 24 * time.Hour
+
+// IssueRefreshToken starts a new token family for the user, as on login.
+// Only the token's hash is stored, so it is returned to the caller once.
+func (u *User) IssueRefreshToken() (string, error) {
+	familyID, err := utils.GenerateSecureToken(16)
+	if err != nil {
+		utils.Logger.Error("Failed to generate refresh token family", "user_id", u.ID, "error", err)
+		return "", err
+	}
+
+	token, err := insertRefreshToken(db.DB, u.ID, familyID, u.TokenVersion)
+	if err != nil {
+		return "", err
+	}
+	utils.Logger.Debug("Refresh token issued", "user_id", u.ID)
+	return token, nil
+}
+
+// execer is satisfied by both *sql.DB and *sql.Tx.
+type execer interface {
+	Exec(query string, args ...any) (sql.Result, error)
+}
+
+func insertRefreshToken(q execer, userID int64, familyID string, tokenVersion int) (string, error) {
+	token, err := utils.GenerateSecureToken(32)
+	if err != nil {
+		utils.Logger.Error("Failed to generate refresh token", "user_id", userID, "error", err)
+		return "", err
+	}
+
+	query := `
+    INSERT INTO refresh_tokens (user_id, family_id, token_hash, token_version, expires_at)
+    VALUES ($1, $2, $3, $4, $5)
+    `
+	expiresAt := time.Now().UTC().Add(RefreshTokenDuration)
+	_, err = q.Exec(query, userID, familyID, utils.HashToken(token), tokenVersion, expiresAt)
+	if err != nil {
+		utils.Logger.Error("Failed to store refresh token", "user_id", userID, "error", err)
+		return "", err
+	}
+	return token, nil
+}
+
+// RotateRefreshToken exchanges a refresh token for a new one in the same
+// family and returns the user it belongs to. A token can be exchanged only
+// once. Presenting it again means it has leaked, so its whole family is
+// revoked and ErrRefreshTokenReused returned; whoever holds the newer token
+// has to log in again too.
+func RotateRefreshToken(token string) (*User, string, error) {
+	tx, err := db.DB.Begin()
+	if err != nil {
+		utils.Logger.Error("Failed to begin refresh transaction", "error", err)
+		return nil, "", err
+	}
+	defer tx.Rollback()
+
+	query := `
+        SELECT rt.id, rt.family_id, rt.token_version, rt.expires_at, rt.used_at IS NOT NULL,
+            rt.revoked_at IS NOT NULL, u.id, u.email, u.role, u.token_version, u.deleted_at IS NOT NULL
+        FROM refresh_tokens rt
+        JOIN users u ON u.id = rt.user_id
+        WHERE rt.token_hash = $1
+        FOR UPDATE OF rt
+    `
+	var tokenID int64
+	var familyID string
+	var tokenVersion int
+	var expiresAt time.Time
+	var used, revoked, deleted bool
+	var u User
+	err = tx.QueryRow(query, utils.HashToken(token)).Scan(&tokenID, &familyID, &tokenVersion, &expiresAt,
+		&used, &revoked, &u.ID, &u.Email, &u.Role, &u.TokenVersion, &deleted)
+	if err == sql.ErrNoRows {
+		return nil, "", ErrInvalidRefreshToken
+	}
+	if err != nil {
+		utils.Logger.Error("Failed to look up refresh token", "error", err)
+		return nil, "", err
+	}
+
+	if used && !revoked {
+		err = revokeRefreshTokenFamily(tx, familyID)
+		if err != nil {
+			return nil, "", err
+		}
+		err = tx.Commit()
+		if err != nil {
+			utils.Logger.Error("Failed to commit refresh token family revocation", "user_id", u.ID, "error", err)
+			return nil, "", err
+		}
+		utils.Logger.Warn("Refresh token reused, family revoked", "user_id", u.ID)
+		return nil, "", ErrRefreshTokenReused
+	}
+	if used || revoked || deleted || tokenVersion != u.TokenVersion || !time.Now().UTC().Before(expiresAt) {
+		return nil, "", ErrInvalidRefreshToken
+	}
+
+	_, err = tx.Exec("UPDATE refresh_tokens SET used_at = NOW() WHERE id = $1", tokenID)
+	if err != nil {
+		utils.Logger.Error("Failed to mark refresh token used", "user_id", u.ID, "error", err)
+		return nil, "", err
+	}
+
+	next, err := insertRefreshToken(tx, u.ID, familyID, u.TokenVersion)
+	if err != nil {
+		return nil, "", err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		utils.Logger.Error("Failed to commit refresh token rotation", "user_id", u.ID, "error", err)
+		return nil, "", err
+	}
+
+	utils.Logger.Debug("Refresh token rotated", "user_id", u.ID)
+	return &u, next, nil
+}
+
+func revokeRefreshTokenFamily(tx *sql.Tx, familyID string) error {
+	query := "UPDATE refresh_tokens SET revoked_at = NOW() WHERE family_id = $1 AND revoked_at IS NULL"
+	_, err := tx.Exec(query, familyID)
+	if err != nil {
+		utils.Logger.Error("Failed to revoke refresh token family", "error", err)
+	}
+	return err
+}
+
+// RevokeRefreshToken revokes the family of one of the user's refresh
+// tokens, ending that session. Unknown tokens are ignored.
+func RevokeRefreshToken(userID int64, token string) error {
+	query := `
+    UPDATE refresh_tokens SET revoked_at = NOW()
+    WHERE revoked_at IS NULL AND family_id IN (
+        SELECT family_id FROM refresh_tokens WHERE token_hash = $1 AND user_id = $2
+    )
+    `
+	_, err := db.DB.Exec(query, utils.HashToken(token), userID)
+	if err != nil {
+		utils.Logger.Error("Failed to revoke refresh token", "user_id", userID, "error", err)
+		return err
+	}
+	utils.Logger.Debug("Refresh token revoked", "user_id", userID)
+	return nil
+}
+
+// RevokeAccessToken denies the access token with the given jti until it
+// expires.
+func RevokeAccessToken(tokenID string, expiresAt time.Time) error {
+	query := "INSERT INTO revoked_tokens (jti, expires_at) VALUES ($1, $2) ON CONFLICT (jti) DO NOTHING"
+	_, err := db.DB.Exec(query, tokenID, expiresAt.UTC())
+	if err != nil {
+		utils.Logger.Error("Failed to revoke access token", "error", err)
+		return err
+	}
+	utils.Logger.Debug("Access token revoked")
+	return nil
+}
+
+// RevokeAllSessions signs the user out everywhere by bumping their token
+// version, which invalidates every access and refresh token issued so far.
+func (u *User) RevokeAllSessions() error {
+	query := "UPDATE users SET token_version = token_version + 1 WHERE id = $1 RETURNING token_version"
+	err := db.DB.QueryRow(query, u.ID).Scan(&u.TokenVersion)
+	if err != nil {
+		utils.Logger.Error("Failed to revoke user sessions", "user_id", u.ID, "error", err)
+		return err
+	}
+	utils.Logger.Debug("User sessions revoked", "user_id", u.ID)
+	return nil
+}
+
+// IsAccessTokenRevoked reports whether a validly signed access token has
+// been revoked, either on its own or by a newer token version. Tokens of
+// deleted users count as revoked.
+func IsAccessTokenRevoked(claims *utils.AccessClaims) (bool, error) {
+	query := `
+        SELECT EXISTS (SELECT 1 FROM revoked_tokens WHERE jti = $1)
+            OR (SELECT token_version FROM users WHERE id = $2 AND deleted_at IS NULL) IS DISTINCT FROM $3
+    `
+	var revoked bool
+	err := db.DB.QueryRow(query, claims.TokenID, claims.UserID, claims.TokenVersion).Scan(&revoked)
+	if err != nil {
+		utils.Logger.Error("Failed to check token revocation", "user_id", claims.UserID, "error", err)
+		return false, err
+	}
+	return revoked, nil
+}
+
+// PurgeExpiredTokens removes refresh tokens and access token denials that
+// have expired, as they can no longer be used either way.
+func PurgeExpiredTokens(now time.Time) (int64, error) {
+	now = now.UTC()
+	res, err := db.DB.Exec("DELETE FROM refresh_tokens WHERE expires_at < $1", now)
+	if err != nil {
+		utils.Logger.Error("Failed to purge expired refresh tokens", "error", err)
+		return 0, err
+	}
+	refreshed, err := res.RowsAffected()
+	if err != nil {
+		return 0, err
+	}
+
+	res, err = db.DB.Exec("DELETE FROM revoked_tokens WHERE expires_at < $1", now)
+	if err != nil {
+		utils.Logger.Error("Failed to purge expired token denials", "error", err)
+		return 0, err
+	}
+	denied, err := res.RowsAffected()
+	if err != nil {
+		return 0, err
+	}
+	return refreshed + denied, nil
+}
@@ -0,0 +1,258 @@
+package models
+
+import (
+	"database/sql"
+	"errors"
+	"time"
+
+	"example.com/event-booking-api/db"
+	"example.com/event-booking-api/utils"
+)
+
+var (
+	ErrNotDeleted   = errors.New("record is not deleted")
+	ErrOwnerDeleted = errors.New("the event's owner is deleted")
+)
+
+type DeletedEvent struct {
+	Event
+	DeletedAt time.Time `json:"deleted_at"`
+}
+
+type DeletedUser struct {
+	PublicUser
+	DeletedAt time.Time `json:"deleted_at"`
+}
+
+// PurgeResult counts the records removed by PurgeDeleted.
+type PurgeResult struct {
+	Events int64
+	Users  int64
+}
+
+func GetDeletedEvents() ([]DeletedEvent, error) {
+	query := "SELECT " + eventColumns + ", deleted_at FROM events WHERE deleted_at IS NOT NULL ORDER BY deleted_at DESC, id"
+	rows, err := db.DB.Query(query)
+	if err != nil {
+		utils.Logger.Error("Failed to query deleted events", "error", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	deleted := []DeletedEvent{}
+	for rows.Next() {
+		var d DeletedEvent
+		e, err := scanEvent(rows, &d.DeletedAt)
+		if err != nil {
+			utils.Logger.Error("Failed to scan deleted event row", "error", err)
+			return nil, err
+		}
+		d.Event = *e
+		deleted = append(deleted, d)
+	}
+
+	events := make([]Event, len(deleted))
+	for i, d := range deleted {
+		events[i] = d.Event
+	}
+	err = loadEventTags(events)
+	if err != nil {
+		return nil, err
+	}
+	for i := range deleted {
+		deleted[i].Tags = events[i].Tags
+	}
+
+	utils.Logger.Debug("Retrieved deleted events", "count", len(deleted))
+	return deleted, nil
+}
+
+func GetDeletedUsers() ([]DeletedUser, error) {
+	query := "SELECT id, email, role, email_verified_at IS NOT NULL, deleted_at FROM users WHERE deleted_at IS NOT NULL ORDER BY deleted_at DESC, id"
+	rows, err := db.DB.Query(query)
+	if err != nil {
+		utils.Logger.Error("Failed to query deleted users", "error", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	deleted := []DeletedUser{}
+	for rows.Next() {
+		var d DeletedUser
+		err := rows.Scan(&d.ID, &d.Email, &d.Role, &d.EmailVerified, &d.DeletedAt)
+		if err != nil {
+			utils.Logger.Error("Failed to scan deleted user row", "error", err)
+			return nil, err
+		}
+		deleted = append(deleted, d)
+	}
+
+	utils.Logger.Debug("Retrieved deleted users", "count", len(deleted))
+	return deleted, nil
+}
+
+// RestoreEvent brings back a soft-deleted event. Events of a deleted user
+// can only come back by restoring the user.
+func RestoreEvent(id int64) error {
+	tx, err := db.DB.Begin()
+	if err != nil {
+		utils.Logger.Error("Failed to begin event restore transaction", "event_id", id, "error", err)
+		return err
+	}
+	defer tx.Rollback()
+
+	query := `
+        SELECT u.deleted_at IS NOT NULL
+        FROM events e
+        JOIN users u ON u.id = e.user_id
+        WHERE e.id = $1 AND e.deleted_at IS NOT NULL
+        FOR UPDATE OF e
+    `
+	var ownerDeleted bool
+	err = tx.QueryRow(query, id).Scan(&ownerDeleted)
+	if err == sql.ErrNoRows {
+		return ErrNotDeleted
+	}
+	if err != nil {
+		utils.Logger.Error("Failed to look up deleted event", "event_id", id, "error", err)
+		return err
+	}
+	if ownerDeleted {
+		return ErrOwnerDeleted
+	}
+
+	_, err = tx.Exec("UPDATE events SET deleted_at = NULL WHERE id = $1", id)
+	if err != nil {
+		utils.Logger.Error("Failed to restore event", "event_id", id, "error", err)
+		return err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		utils.Logger.Error("Failed to commit event restore", "event_id", id, "error", err)
+		return err
+	}
+	utils.Logger.Debug("Event restored", "event_id", id)
+	return nil
+}
+
+// RestoreUser brings back a soft-deleted user and the events that were
+// deleted along with them. Events the user had deleted earlier stay deleted.
+func RestoreUser(id int64) error {
+	tx, err := db.DB.Begin()
+	if err != nil {
+		utils.Logger.Error("Failed to begin user restore transaction", "user_id", id, "error", err)
+		return err
+	}
+	defer tx.Rollback()
+
+	query := `
+        UPDATE events e SET deleted_at = NULL
+        FROM users u
+        WHERE u.id = $1 AND u.deleted_at IS NOT NULL
+          AND e.user_id = u.id AND e.deleted_at = u.deleted_at
+    `
+	_, err = tx.Exec(query, id)
+	if err != nil {
+		utils.Logger.Error("Failed to restore user's events", "user_id", id, "error", err)
+		return err
+	}
+
+	query = "UPDATE users SET deleted_at = NULL WHERE id = $1 AND deleted_at IS NOT NULL"
+	result, err := tx.Exec(query, id)
+	if isUniqueViolation(err) {
+		return ErrEmailTaken
+	}
+	if err != nil {
+		utils.Logger.Error("Failed to restore user", "user_id", id, "error", err)
+		return err
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return ErrNotDeleted
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		utils.Logger.Error("Failed to commit user restore", "user_id", id, "error", err)
+		return err
+	}
+	utils.Logger.Debug("User restored", "user_id", id)
+	return nil
+}
+
+// PurgeDeleted permanently removes events and users deleted before the given
+// time, along with everything that cascades from them. Seats held by purged
+// users are handed to the waitlist of the events they were registered for.
+func PurgeDeleted(before time.Time) (*PurgeResult, error) {
+	tx, err := db.DB.Begin()
+	if err != nil {
+		utils.Logger.Error("Failed to begin purge transaction", "error", err)
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	query := `
+        SELECT DISTINCT r.event_id
+        FROM registrations r
+        JOIN users u ON u.id = r.user_id
+        JOIN events e ON e.id = r.event_id
+        WHERE u.deleted_at < $1 AND e.deleted_at IS NULL
+    `
+	rows, err := tx.Query(query, before)
+	if err != nil {
+		utils.Logger.Error("Failed to query events affected by purge", "error", err)
+		return nil, err
+	}
+	affected := []int64{}
+	for rows.Next() {
+		var eventID int64
+		err := rows.Scan(&eventID)
+		if err != nil {
+			rows.Close()
+			return nil, err
+		}
+		affected = append(affected, eventID)
+	}
+	rows.Close()
+
+	result := &PurgeResult{}
+
+	res, err := tx.Exec("DELETE FROM events WHERE deleted_at < $1", before)
+	if err != nil {
+		utils.Logger.Error("Failed to purge deleted events", "error", err)
+		return nil, err
+	}
+	result.Events, err = res.RowsAffected()
+	if err != nil {
+		return nil, err
+	}
+
+	res, err = tx.Exec("DELETE FROM users WHERE deleted_at < $1", before)
+	if err != nil {
+		utils.Logger.Error("Failed to purge deleted users", "error", err)
+		return nil, err
+	}
+	result.Users, err = res.RowsAffected()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, eventID := range affected {
+		_, err = promoteWaitlisted(tx, eventID)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		utils.Logger.Error("Failed to commit purge", "error", err)
+		return nil, err
+	}
+	utils.Logger.Debug("Purged deleted records", "events", result.Events, "users", result.Users)
+	return result, nil
+}
@@ -0,0 +1,125 @@
+package models
+
+import (
+	"database/sql"
+	"sort"
+	"strings"
+
+	"example.com/event-booking-api/db"
+	"example.com/event-booking-api/utils"
+	"github.com/lib/pq"
+)
+
+type TagCount struct {
+	Tag   string `json:"tag"`
+	Count int    `json:"count"`
+}
+
+// NormalizeTags lower-cases and trims tags, dropping blanks and duplicates.
+// The result is sorted.
+func NormalizeTags(tags []string) []string {
+	seen := map[string]bool{}
+	normalized := []string{}
+	for _, tag := range tags {
+		tag = strings.ToLower(strings.TrimSpace(tag))
+		if tag == "" || seen[tag] {
+			continue
+		}
+		seen[tag] = true
+		normalized = append(normalized, tag)
+	}
+	sort.Strings(normalized)
+	return normalized
+}
+
+// replaceEventTags swaps the stored tags of an event for the given ones.
+func replaceEventTags(tx *sql.Tx, eventID int64, tags []string) error {
+	_, err := tx.Exec("DELETE FROM event_tags WHERE event_id = $1", eventID)
+	if err != nil {
+		utils.Logger.Error("Failed to clear event tags", "event_id", eventID, "error", err)
+		return err
+	}
+	if len(tags) == 0 {
+		return nil
+	}
+
+	query := "INSERT INTO event_tags (event_id, tag) SELECT $1, unnest($2::text[])"
+	_, err = tx.Exec(query, eventID, pq.Array(tags))
+	if err != nil {
+		utils.Logger.Error("Failed to save event tags", "event_id", eventID, "error", err)
+		return err
+	}
+	return nil
+}
+
+// loadEventTags fills in the tags of every event in the slice.
+func loadEventTags(events []Event) error {
+	if len(events) == 0 {
+		return nil
+	}
+	ids := make([]int64, len(events))
+	for i, e := range events {
+		ids[i] = e.ID
+	}
+
+	query := "SELECT event_id, tag FROM event_tags WHERE event_id = ANY($1) ORDER BY tag"
+	rows, err := db.DB.Query(query, pq.Array(ids))
+	if err != nil {
+		utils.Logger.Error("Failed to query event tags", "error", err)
+		return err
+	}
+	defer rows.Close()
+
+	tags := map[int64][]string{}
+	for rows.Next() {
+		var eventID int64
+		var tag string
+		err := rows.Scan(&eventID, &tag)
+		if err != nil {
+			utils.Logger.Error("Failed to scan event tag row", "error", err)
+			return err
+		}
+		tags[eventID] = append(tags[eventID], tag)
+	}
+
+	for i := range events {
+		events[i].Tags = tags[events[i].ID]
+		if events[i].Tags == nil {
+			events[i].Tags = []string{}
+		}
+	}
+	return nil
+}
+
+// GetTagCounts lists every tag in use with the number of published events
+// carrying it, most used first.
+func GetTagCounts() ([]TagCount, error) {
+	query := `
+        SELECT t.tag, COUNT(*) AS count
+        FROM event_tags t
+        JOIN events e ON e.id = t.event_id
+        WHERE e.status = $1 AND e.deleted_at IS NULL
+        GROUP BY t.tag
+        ORDER BY count DESC, tag
+    `
+	rows, err := db.DB.Query(query, EventPublished)
+	if err != nil {
+		utils.Logger.Error("Failed to query tag counts", "error", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	counts := []TagCount{}
+	for rows.Next() {
+		var tc TagCount
+		err := rows.Scan(&tc.Tag, &tc.Count)
+		if err != nil {
+			utils.Logger.Error("Failed to scan tag count row", "error", err)
+			return nil, err
+		}
+		counts = append(counts, tc)
+	}
+
+	utils.Logger.Debug("Retrieved tag counts", "count", len(counts))
+	return counts, nil
+}
@@ -0,0 +1,290 @@
+package models
+
+import (
+	"database/sql"
+	"errors"
+	"strings"
+	"time"
+
+	"example.com/event-booking-api/db"
+	"example.com/event-booking-api/utils"
+)
+
+var (
+	ErrDuplicateTicketType = errors.New("a ticket type with this name already exists for the event")
+	ErrInvalidSalesWindow  = errors.New("ticket sales must start before they end")
+	ErrTicketTypeInUse     = errors.New("ticket type has registrations")
+	ErrTicketTypeRequired  = errors.New("a ticket type is required for this event")
+	ErrUnknownTicketType   = errors.New("ticket type does not exist for this event")
+	ErrTicketSalesClosed   = errors.New("ticket type is not on sale")
+)
+
+// ticketTypeColumns lists the columns scanned by scanTicketType, in order.
+const ticketTypeColumns = "id, event_id, name, price_cents, currency, quantity, sales_start, sales_end"
+
+// TicketType is a kind of ticket sold for an event, such as General or VIP.
+// Quantity and the sales window are optional.
+type TicketType struct {
+	ID         int64      `json:"id"`
+	EventID    int64      `json:"event_id"`
+	Name       string     `json:"name" binding:"required,max=100"`
+	PriceCents int        `json:"price_cents" binding:"gte=0"`
+	Currency   string     `json:"currency" binding:"required,iso4217"`
+	Quantity   *int       `json:"quantity" binding:"omitempty,gt=0"`
+	SalesStart *time.Time `json:"sales_start"`
+	SalesEnd   *time.Time `json:"sales_end"`
+}
+
+// TicketSales sums up the registrations of one ticket type. Confirmed and
+// Waitlisted count seats, so guests are included. RevenueCents is what the
+// confirmed seats bring in after DiscountCents of promo code discounts.
+type TicketSales struct {
+	TicketTypeID  int64  `json:"ticket_type_id"`
+	Name          string `json:"name"`
+	PriceCents    int    `json:"price_cents"`
+	Currency      string `json:"currency"`
+	Quantity      *int   `json:"quantity"`
+	Confirmed     int    `json:"confirmed"`
+	Waitlisted    int    `json:"waitlisted"`
+	DiscountCents int64  `json:"discount_cents"`
+	RevenueCents  int64  `json:"revenue_cents"`
+}
+
+func (t *TicketType) validate() error {
+	t.Currency = strings.ToUpper(t.Currency)
+	if t.SalesStart != nil && t.SalesEnd != nil && !t.SalesStart.Before(*t.SalesEnd) {
+		return ErrInvalidSalesWindow
+	}
+	return nil
+}
+
+// OnSale reports whether the ticket type can be bought at the given time.
+func (t *TicketType) OnSale(at time.Time) bool {
+	if t.SalesStart != nil && at.Before(*t.SalesStart) {
+		return false
+	}
+	if t.SalesEnd != nil && !at.Before(*t.SalesEnd) {
+		return false
+	}
+	return true
+}
+
+func (t *TicketType) Save() error {
+	err := t.validate()
+	if err != nil {
+		return err
+	}
+
+	query := `
+    INSERT INTO ticket_types (event_id, name, price_cents, currency, quantity, sales_start, sales_end)
+    VALUES ($1, $2, $3, $4, $5, $6, $7)
+    RETURNING id
+    `
+	err = db.DB.QueryRow(query, t.EventID, t.Name, t.PriceCents, t.Currency, t.Quantity,
+		t.SalesStart, t.SalesEnd).Scan(&t.ID)
+	if isUniqueViolation(err) {
+		return ErrDuplicateTicketType
+	}
+	if err != nil {
+		utils.Logger.Error("Failed to save ticket type to database",
+			"event_id", t.EventID,
+			"name", t.Name,
+			"error", err)
+		return err
+	}
+	utils.Logger.Debug("Ticket type saved to database", "ticket_type_id", t.ID, "event_id", t.EventID)
+	return nil
+}
+
+// Update changes the ticket type. Lowering the quantity below the number
+// already sold is allowed; it only stops further sales. Raising it moves
+// people waiting for this type off the waitlist.
+func (t *TicketType) Update() error {
+	err := t.validate()
+	if err != nil {
+		return err
+	}
+
+	tx, err := db.DB.Begin()
+	if err != nil {
+		utils.Logger.Error("Failed to begin ticket type update transaction", "ticket_type_id", t.ID, "error", err)
+		return err
+	}
+	defer tx.Rollback()
+
+	query := `
+    UPDATE ticket_types
+    SET name = $1, price_cents = $2, currency = $3, quantity = $4, sales_start = $5, sales_end = $6
+    WHERE id = $7 AND event_id = $8
+    `
+	_, err = tx.Exec(query, t.Name, t.PriceCents, t.Currency, t.Quantity,
+		t.SalesStart, t.SalesEnd, t.ID, t.EventID)
+	if isUniqueViolation(err) {
+		return ErrDuplicateTicketType
+	}
+	if err != nil {
+		utils.Logger.Error("Failed to update ticket type in database", "ticket_type_id", t.ID, "error", err)
+		return err
+	}
+
+	_, err = promoteWaitlisted(tx, t.EventID)
+	if err != nil {
+		return err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		utils.Logger.Error("Failed to commit ticket type update", "ticket_type_id", t.ID, "error", err)
+		return err
+	}
+	utils.Logger.Debug("Ticket type updated in database", "ticket_type_id", t.ID, "event_id", t.EventID)
+	return nil
+}
+
+func (t *TicketType) Delete() error {
+	query := `DELETE FROM ticket_types WHERE id = $1 AND event_id = $2`
+	_, err := db.DB.Exec(query, t.ID, t.EventID)
+	if isForeignKeyViolation(err) {
+		return ErrTicketTypeInUse
+	}
+	if err != nil {
+		utils.Logger.Error("Failed to delete ticket type from database", "ticket_type_id", t.ID, "error", err)
+		return err
+	}
+	utils.Logger.Debug("Ticket type deleted from database", "ticket_type_id", t.ID, "event_id", t.EventID)
+	return nil
+}
+
+func scanTicketType(row rowScanner) (*TicketType, error) {
+	var t TicketType
+	var quantity sql.NullInt64
+	var salesStart, salesEnd sql.NullTime
+	err := row.Scan(&t.ID, &t.EventID, &t.Name, &t.PriceCents, &t.Currency, &quantity, &salesStart, &salesEnd)
+	if err != nil {
+		return nil, err
+	}
+	if quantity.Valid {
+		q := int(quantity.Int64)
+		t.Quantity = &q
+	}
+	if salesStart.Valid {
+		t.SalesStart = &salesStart.Time
+	}
+	if salesEnd.Valid {
+		t.SalesEnd = &salesEnd.Time
+	}
+	return &t, nil
+}
+
+func GetTicketTypesByEventID(eventID int64) ([]TicketType, error) {
+	query := "SELECT " + ticketTypeColumns + " FROM ticket_types WHERE event_id = $1 ORDER BY price_cents, id"
+	rows, err := db.DB.Query(query, eventID)
+	if err != nil {
+		utils.Logger.Error("Failed to query ticket types", "event_id", eventID, "error", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	ticketTypes := []TicketType{}
+	for rows.Next() {
+		t, err := scanTicketType(rows)
+		if err != nil {
+			utils.Logger.Error("Failed to scan ticket type row", "error", err)
+			return nil, err
+		}
+		ticketTypes = append(ticketTypes, *t)
+	}
+
+	utils.Logger.Debug("Retrieved ticket types", "event_id", eventID, "count", len(ticketTypes))
+	return ticketTypes, nil
+}
+
+// GetTicketType looks up a ticket type of the given event. It returns
+// ErrUnknownTicketType if the event has no such ticket type.
+func GetTicketType(eventID, id int64) (*TicketType, error) {
+	query := "SELECT " + ticketTypeColumns + " FROM ticket_types WHERE id = $1 AND event_id = $2"
+	t, err := scanTicketType(db.DB.QueryRow(query, id, eventID))
+	if err == sql.ErrNoRows {
+		return nil, ErrUnknownTicketType
+	}
+	if err != nil {
+		utils.Logger.Error("Failed to get ticket type", "event_id", eventID, "ticket_type_id", id, "error", err)
+		return nil, err
+	}
+	return t, nil
+}
+
+// GetTicketSales breaks the event's registrations down by ticket type.
+func GetTicketSales(eventID int64) ([]TicketSales, error) {
+	query := `
+        SELECT t.id, t.name, t.price_cents, t.currency, t.quantity,
+            COALESCE(SUM(r.party_size) FILTER (WHERE r.status = $2), 0),
+            COALESCE(SUM(r.party_size) FILTER (WHERE r.status = $3), 0),
+            COALESCE(SUM(pr.discount_cents) FILTER (WHERE r.status = $2), 0)
+        FROM ticket_types t
+        LEFT JOIN registrations r ON r.ticket_type_id = t.id
+        LEFT JOIN promo_redemptions pr ON pr.registration_id = r.id
+        WHERE t.event_id = $1
+        GROUP BY t.id
+        ORDER BY t.price_cents, t.id
+    `
+	rows, err := db.DB.Query(query, eventID, RegistrationConfirmed, RegistrationWaitlisted)
+	if err != nil {
+		utils.Logger.Error("Failed to query ticket sales", "event_id", eventID, "error", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	sales := []TicketSales{}
+	for rows.Next() {
+		var s TicketSales
+		var quantity sql.NullInt64
+		err := rows.Scan(&s.TicketTypeID, &s.Name, &s.PriceCents, &s.Currency, &quantity, &s.Confirmed, &s.Waitlisted,
+			&s.DiscountCents)
+		if err != nil {
+			utils.Logger.Error("Failed to scan ticket sales row", "error", err)
+			return nil, err
+		}
+		if quantity.Valid {
+			q := int(quantity.Int64)
+			s.Quantity = &q
+		}
+		s.RevenueCents = int64(s.Confirmed)*int64(s.PriceCents) - s.DiscountCents
+		sales = append(sales, s)
+	}
+
+	return sales, nil
+}
+
+// resolveTicketType checks the ticket type picked for a registration. Events
+// without ticket types take registrations without one; events with ticket
+// types require one that is on sale.
+func resolveTicketType(tx *sql.Tx, eventID int64, id *int64) (*TicketType, error) {
+	if id == nil {
+		var hasTypes bool
+		query := "SELECT EXISTS (SELECT 1 FROM ticket_types WHERE event_id = $1)"
+		err := tx.QueryRow(query, eventID).Scan(&hasTypes)
+		if err != nil {
+			utils.Logger.Error("Failed to check for ticket types", "event_id", eventID, "error", err)
+			return nil, err
+		}
+		if hasTypes {
+			return nil, ErrTicketTypeRequired
+		}
+		return nil, nil
+	}
+
+	query := "SELECT " + ticketTypeColumns + " FROM ticket_types WHERE id = $1 AND event_id = $2"
+	t, err := scanTicketType(tx.QueryRow(query, *id, eventID))
+	if err == sql.ErrNoRows {
+		return nil, ErrUnknownTicketType
+	}
+	if err != nil {
+		utils.Logger.Error("Failed to get ticket type", "event_id", eventID, "ticket_type_id", *id, "error", err)
+		return nil, err
+	}
+	if !t.OnSale(time.Now()) {
+		return nil, ErrTicketSalesClosed
+	}
+	return t, nil
+}
@@ -1,21 +1,48 @@
 package models
 
 import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
 	"example.com/event-booking-api/db"
 	"example.com/event-booking-api/utils"
 )
 
+var ErrEmailTaken = errors.New("a user with this email already exists")
+
 type User struct {
 	ID       int64  `json:"id"`
-	Email    string `json:"email" binding:"required"`
+	Email    string `json:"email" binding:"required,email"`
 	Password string `json:"password" binding:"required"`
 	Role     string `json:"role"`
+
+	// Version is bumped on every update. It is exposed as the ETag, not in
+	// the body.
+	Version int `json:"-"`
+
+	EmailVerified bool `json:"email_verified"`
+
+	// ServiceAccount users are integrations. They authenticate with API
+	// keys only.
+	ServiceAccount bool `json:"service_account,omitempty"`
+
+	// TokenVersion is carried by the user's tokens. Bumping it signs the
+	// user out everywhere.
+	TokenVersion int `json:"-"`
 }
 
+// userColumns lists the columns scanned by scanUser, in order.
+const userColumns = "id, email, password, role, version, token_version, email_verified_at IS NOT NULL, service_account"
+
 type PublicUser struct {
-	ID    int64  `json:"id"`
-	Email string `json:"email"`
-	Role  string `json:"role"`
+	ID             int64  `json:"id"`
+	Email          string `json:"email"`
+	Role           string `json:"role"`
+	EmailVerified  bool   `json:"email_verified"`
+	ServiceAccount bool   `json:"service_account,omitempty"`
 }
 
 func (u *User) Save() error {
@@ -36,34 +63,134 @@ func (u *User) Save() error {
 	return nil
 }
 
+// Update overwrites the user's email and password, provided they are still
+// at u.Version. u.Password holds the new plain-text password. On success
+// u.Version holds the new version; ErrVersionConflict means someone else
+// updated the user first.
 func (u *User) Update() error {
-	query := `UPDATE users SET email = $1, password = $2 WHERE id = $3`
-	_, err := db.DB.Exec(query, u.Email, u.Password, u.ID)
+	return u.UpdateFields([]string{"email", "password"})
+}
+
+// userPatchFields lists the user fields a merge patch may set. Roles are
+// changed by admins through their own endpoint.
+var userPatchFields = map[string]patchField{
+	"email":    {field: "Email"},
+	"password": {field: "Password"},
+}
+
+// ApplyMergePatch applies an RFC 7396 merge patch to the user in memory.
+// Call UpdateFields with the changed fields to store it.
+func (u *User) ApplyMergePatch(patch map[string]json.RawMessage) (*MergePatchResult, error) {
+	return applyMergePatch(u, userPatchFields, patch)
+}
+
+// UpdateFields stores the given fields of the user, named as in JSON, and
+// leaves every other column alone. A password is hashed before it is stored.
+// Changing the password or role signs the user out everywhere, since their
+// tokens were issued under the old ones. A new email address has to be
+// verified again. Like Update it requires the user to
+// still be at u.Version.
+func (u *User) UpdateFields(fields []string) error {
+	columns := []string{}
+	values := []any{}
+	revoke := false
+	set := func(column string, value any) {
+		values = append(values, value)
+		columns = append(columns, fmt.Sprintf("%s = $%d", column, len(values)))
+	}
+
+	for _, field := range fields {
+		switch field {
+		case "email":
+			set("email", u.Email)
+			// SET sees the old row, so this compares against the old address
+			columns = append(columns, fmt.Sprintf(
+				"email_verified_at = CASE WHEN email = $%d THEN email_verified_at END", len(values)))
+		case "password":
+			hashedPassword, err := utils.HashPassword(u.Password)
+			if err != nil {
+				utils.Logger.Error("Failed to hash password", "user_id", u.ID, "error", err)
+				return err
+			}
+			set("password", hashedPassword)
+			revoke = true
+		case "role":
+			set("role", u.Role)
+			revoke = true
+		}
+	}
+	extra := []string{"version = version + 1", "updated_at = NOW()"}
+	if revoke {
+		extra = append(extra, "token_version = token_version + 1")
+	}
+
+	values = append(values, u.ID, u.Version)
+	query := fmt.Sprintf(`
+    UPDATE users SET %s
+    WHERE id = $%d AND version = $%d AND deleted_at IS NULL
+    RETURNING version, token_version, email_verified_at IS NOT NULL
+    `, strings.Join(append(columns, extra...), ", "),
+		len(values)-1, len(values))
+	err := db.DB.QueryRow(query, values...).Scan(&u.Version, &u.TokenVersion, &u.EmailVerified)
+	if err == sql.ErrNoRows {
+		return ErrVersionConflict
+	}
+	if isUniqueViolation(err) {
+		return ErrEmailTaken
+	}
+	if isForeignKeyViolation(err) {
+		return ErrUnknownRole
+	}
 	if err != nil {
-		utils.Logger.Error("Failed to update user in database", "user_id", u.ID, "email", u.Email, "error", err)
+		utils.Logger.Error("Failed to update user in database", "user_id", u.ID, "fields", fields, "error", err)
 		return err
 	}
-	utils.Logger.Debug("User updated in database", "user_id", u.ID, "email", u.Email)
+	utils.Logger.Debug("User updated in database", "user_id", u.ID, "fields", fields)
 	return nil
 }
 
+// Delete soft-deletes the user together with their events. The events get
+// the same deletion time as the user, which is how RestoreUser finds them
+// again. The user's registrations keep their seats until the account is
+// purged. The user's tokens are revoked and stay so after a restore.
 func (u *User) Delete() error {
-	query := `DELETE FROM users WHERE id = $1`
-	_, err := db.DB.Exec(query, u.ID)
+	tx, err := db.DB.Begin()
+	if err != nil {
+		utils.Logger.Error("Failed to begin user deletion transaction", "user_id", u.ID, "error", err)
+		return err
+	}
+	defer tx.Rollback()
+
+	// NOW() is fixed for the whole transaction, so both statements agree
+	query := `UPDATE users SET deleted_at = NOW(), token_version = token_version + 1 WHERE id = $1 AND deleted_at IS NULL`
+	_, err = tx.Exec(query, u.ID)
 	if err != nil {
 		utils.Logger.Error("Failed to delete user from database", "user_id", u.ID, "error", err)
 		return err
 	}
+
+	query = `UPDATE events SET deleted_at = NOW() WHERE user_id = $1 AND deleted_at IS NULL`
+	_, err = tx.Exec(query, u.ID)
+	if err != nil {
+		utils.Logger.Error("Failed to delete user's events", "user_id", u.ID, "error", err)
+		return err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		utils.Logger.Error("Failed to commit user deletion", "user_id", u.ID, "error", err)
+		return err
+	}
 	utils.Logger.Debug("User deleted from database", "user_id", u.ID)
 	return nil
 }
 
 func (u *User) Authenticate() error {
-	query := "SELECT id, password, role FROM users WHERE email = $1"
+	query := "SELECT id, password, role, token_version FROM users WHERE email = $1 AND NOT service_account AND deleted_at IS NULL"
 	row := db.DB.QueryRow(query, u.Email)
 
 	var storedHashedPassword string
-	err := row.Scan(&u.ID, &storedHashedPassword, &u.Role)
+	err := row.Scan(&u.ID, &storedHashedPassword, &u.Role, &u.TokenVersion)
 	if err != nil {
 		utils.Logger.Error("Failed to retrieve user for authentication", "email", u.Email, "error", err)
 		return err
@@ -79,16 +206,47 @@ func (u *User) Authenticate() error {
 	return nil
 }
 
+// RotateCalendarToken issues a new secret token for the user's calendar feed,
+// invalidating the previous one. Only its hash is stored, so the token is
+// returned to the caller once.
+func (u *User) RotateCalendarToken() (string, error) {
+	token, err := utils.GenerateSecureToken(32)
+	if err != nil {
+		utils.Logger.Error("Failed to generate calendar token", "user_id", u.ID, "error", err)
+		return "", err
+	}
+
+	query := `UPDATE users SET calendar_token_hash = $1 WHERE id = $2 AND deleted_at IS NULL`
+	_, err = db.DB.Exec(query, utils.HashToken(token), u.ID)
+	if err != nil {
+		utils.Logger.Error("Failed to store calendar token", "user_id", u.ID, "error", err)
+		return "", err
+	}
+	utils.Logger.Debug("Calendar token rotated", "user_id", u.ID)
+	return token, nil
+}
+
 func (u *User) ToPublic() *PublicUser {
 	return &PublicUser{
-		ID:    u.ID,
-		Email: u.Email,
-		Role:  u.Role,
+		ID:             u.ID,
+		Email:          u.Email,
+		Role:           u.Role,
+		EmailVerified:  u.EmailVerified,
+		ServiceAccount: u.ServiceAccount,
 	}
 }
 
+func scanUser(row rowScanner) (*User, error) {
+	var u User
+	err := row.Scan(&u.ID, &u.Email, &u.Password, &u.Role, &u.Version, &u.TokenVersion, &u.EmailVerified, &u.ServiceAccount)
+	if err != nil {
+		return nil, err
+	}
+	return &u, nil
+}
+
 func GetAllUsers() ([]User, error) {
-	query := `SELECT * FROM users`
+	query := "SELECT " + userColumns + " FROM users WHERE deleted_at IS NULL"
 	rows, err := db.DB.Query(query)
 	if err != nil {
 		utils.Logger.Error("Failed to query all users", "error", err)
@@ -99,13 +257,12 @@ func GetAllUsers() ([]User, error) {
 
 	users := []User{}
 	for rows.Next() {
-		var u User
-		err := rows.Scan(&u.ID, &u.Email, &u.Password, &u.Role)
+		u, err := scanUser(rows)
 		if err != nil {
 			utils.Logger.Error("Failed to scan user row", "error", err)
 			return nil, err
 		}
-		users = append(users, u)
+		users = append(users, *u)
 	}
 
 	utils.Logger.Debug("Retrieved all users from database", "count", len(users))
@@ -113,31 +270,43 @@ func GetAllUsers() ([]User, error) {
 }
 
 func GetUserByID(id int64) (*User, error) {
-	query := `SELECT * FROM users WHERE id = $1`
+	query := "SELECT " + userColumns + " FROM users WHERE id = $1 AND deleted_at IS NULL"
 	row := db.DB.QueryRow(query, id)
 
-	var u User
-	err := row.Scan(&u.ID, &u.Email, &u.Password, &u.Role)
+	u, err := scanUser(row)
 	if err != nil {
 		utils.Logger.Error("Failed to get user by ID", "user_id", id, "error", err)
 		return nil, err
 	}
 
 	utils.Logger.Debug("Retrieved user by ID", "user_id", id, "email", u.Email)
-	return &u, nil
+	return u, nil
 }
 
 func GetUserByEmail(email string) (*User, error) {
-	query := `SELECT * FROM users WHERE email = $1`
+	query := "SELECT " + userColumns + " FROM users WHERE email = $1 AND deleted_at IS NULL"
 	row := db.DB.QueryRow(query, email)
 
-	var u User
-	err := row.Scan(&u.ID, &u.Email, &u.Password, &u.Role)
+	u, err := scanUser(row)
 	if err != nil {
 		utils.Logger.Debug("User not found by email", "email", email, "error", err)
 		return nil, err
 	}
 
 	utils.Logger.Debug("Retrieved user by email", "user_id", u.ID, "email", email)
-	return &u, nil
+	return u, nil
+}
+
+func GetUserByCalendarToken(token string) (*User, error) {
+	query := "SELECT " + userColumns + " FROM users WHERE calendar_token_hash = $1 AND deleted_at IS NULL"
+	row := db.DB.QueryRow(query, utils.HashToken(token))
+
+	u, err := scanUser(row)
+	if err != nil {
+		utils.Logger.Debug("User not found by calendar token", "error", err)
+		return nil, err
+	}
+
+	utils.Logger.Debug("Retrieved user by calendar token", "user_id", u.ID)
+	return u, nil
 }
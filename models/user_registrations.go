@@ -0,0 +1,134 @@
+package models
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"example.com/event-booking-api/db"
+	"example.com/event-booking-api/utils"
+)
+
+// UserRegistrationFilter describes a page of a user's registrations. It is
+// bound from the query string of GET /users/:id/registrations.
+type UserRegistrationFilter struct {
+	// When picks registrations that are still ahead ("upcoming") or already
+	// over ("past"). Series registrations are upcoming until the series ends.
+	When   string `form:"when" binding:"omitempty,oneof=upcoming past"`
+	Status string `form:"status" binding:"omitempty,oneof=pending confirmed waitlisted rejected cancelled"`
+	Limit  int    `form:"limit" binding:"omitempty,min=1,max=100"`
+	Offset int    `form:"offset" binding:"omitempty,min=0"`
+}
+
+// UserRegistration is one of a user's registrations with the event it is for.
+type UserRegistration struct {
+	ID             int64      `json:"id"`
+	OccurrenceDate *time.Time `json:"occurrence_date,omitempty"`
+	TicketTypeID   *int64     `json:"ticket_type_id,omitempty"`
+	PartySize      int        `json:"party_size"`
+	Status         string     `json:"status"`
+	RegisteredAt   time.Time  `json:"registered_at"`
+	Event          Event      `json:"event"`
+}
+
+type UserRegistrationPage struct {
+	Registrations []UserRegistration `json:"registrations"`
+	Total         int                `json:"total"`
+	Limit         int                `json:"limit"`
+	Offset        int                `json:"offset"`
+}
+
+// userRegistrationEnd is when a registration is over: the occurrence it is
+// for, or the end of the event or series. Series without an end never are.
+const userRegistrationEnd = `COALESCE(r.occurrence_date,
+    CASE WHEN recurrence_rule IS NULL THEN date ELSE COALESCE(recurrence_ends_at, 'infinity') END)`
+
+func GetRegistrationsForUser(userID int64, f UserRegistrationFilter) (*UserRegistrationPage, error) {
+	if f.Limit <= 0 {
+		f.Limit = DefaultEventPageSize
+	}
+	if f.Limit > MaxEventPageSize {
+		f.Limit = MaxEventPageSize
+	}
+
+	args := []any{userID}
+	conditions := []string{"deleted_at IS NULL"}
+	order := " ORDER BY COALESCE(r.occurrence_date, date) ASC, r.registration_id ASC"
+	switch f.When {
+	case "upcoming":
+		args = append(args, time.Now().UTC())
+		conditions = append(conditions, fmt.Sprintf("%s >= $%d", userRegistrationEnd, len(args)))
+	case "past":
+		args = append(args, time.Now().UTC())
+		conditions = append(conditions, fmt.Sprintf("%s < $%d", userRegistrationEnd, len(args)))
+		order = " ORDER BY COALESCE(r.occurrence_date, date) DESC, r.registration_id DESC"
+	}
+	if f.Status != "" {
+		args = append(args, f.Status)
+		conditions = append(conditions, fmt.Sprintf("r.registration_status = $%d", len(args)))
+	}
+
+	// The registration columns are renamed so they cannot clash with the
+	// event columns selected next to them
+	from := `
+        FROM (
+            SELECT id AS registration_id, event_id, occurrence_date, ticket_type_id, party_size,
+                status AS registration_status, registered_at
+            FROM registrations
+            WHERE user_id = $1
+        ) r
+        JOIN events ON events.id = r.event_id` + whereClause(conditions)
+
+	page := &UserRegistrationPage{Registrations: []UserRegistration{}, Limit: f.Limit, Offset: f.Offset}
+	err := db.DB.QueryRow("SELECT COUNT(*)"+from, args...).Scan(&page.Total)
+	if err != nil {
+		utils.Logger.Error("Failed to count user registrations", "user_id", userID, "error", err)
+		return nil, err
+	}
+
+	args = append(args, f.Limit, f.Offset)
+	query := `
+        SELECT ` + eventColumns + `, r.registration_id, r.occurrence_date, r.ticket_type_id, r.party_size,
+            r.registration_status, r.registered_at` + from + order +
+		fmt.Sprintf(" LIMIT $%d OFFSET $%d", len(args)-1, len(args))
+	rows, err := db.DB.Query(query, args...)
+	if err != nil {
+		utils.Logger.Error("Failed to query user registrations", "user_id", userID, "error", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	events := []Event{}
+	for rows.Next() {
+		var r UserRegistration
+		var occurrence sql.NullTime
+		var ticketTypeID sql.NullInt64
+		e, err := scanEvent(rows, &r.ID, &occurrence, &ticketTypeID, &r.PartySize, &r.Status, &r.RegisteredAt)
+		if err != nil {
+			utils.Logger.Error("Failed to scan user registration row", "error", err)
+			return nil, err
+		}
+		if occurrence.Valid {
+			r.OccurrenceDate = &occurrence.Time
+		}
+		if ticketTypeID.Valid {
+			r.TicketTypeID = &ticketTypeID.Int64
+		}
+		page.Registrations = append(page.Registrations, r)
+		events = append(events, *e)
+	}
+
+	err = loadEventTags(events)
+	if err != nil {
+		return nil, err
+	}
+	for i := range page.Registrations {
+		page.Registrations[i].Event = events[i]
+	}
+
+	utils.Logger.Debug("Retrieved user registrations",
+		"user_id", userID,
+		"count", len(page.Registrations),
+		"total", page.Total)
+	return page, nil
+}
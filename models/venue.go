@@ -0,0 +1,224 @@
+package models
+
+import (
+	"database/sql"
+	"errors"
+
+	"example.com/event-booking-api/db"
+	"example.com/event-booking-api/utils"
+)
+
+var (
+	ErrUnknownVenue = errors.New("venue does not exist")
+	ErrVenueInUse   = errors.New("venue is used by an event")
+	ErrInvalidVenue = errors.New("venue layout has duplicate names")
+)
+
+// Venue is a seating layout that events can be held in. Sections are made
+// of rows, and rows of seats, each in display order.
+type Venue struct {
+	ID       int64          `json:"id"`
+	Name     string         `json:"name" binding:"required,max=200"`
+	UserID   *int64         `json:"user_id"`
+	Sections []VenueSection `json:"sections,omitempty" binding:"required,min=1,max=50,dive"`
+}
+
+type VenueSection struct {
+	ID   int64      `json:"id"`
+	Name string     `json:"name" binding:"required,max=100"`
+	Rows []VenueRow `json:"rows" binding:"required,min=1,max=200,dive"`
+}
+
+type VenueRow struct {
+	ID    int64       `json:"id"`
+	Label string      `json:"label" binding:"required,max=20"`
+	Seats []VenueSeat `json:"seats" binding:"required,min=1,max=500,dive"`
+}
+
+type VenueSeat struct {
+	ID    int64  `json:"id"`
+	Label string `json:"label" binding:"required,max=20"`
+}
+
+// VenueSummary is a venue without its layout, for listings.
+type VenueSummary struct {
+	ID     int64  `json:"id"`
+	Name   string `json:"name"`
+	UserID *int64 `json:"user_id"`
+	Seats  int    `json:"seats"`
+}
+
+// Save stores the venue with its whole layout and fills in the IDs.
+func (v *Venue) Save() error {
+	tx, err := db.DB.Begin()
+	if err != nil {
+		utils.Logger.Error("Failed to begin venue save transaction", "name", v.Name, "error", err)
+		return err
+	}
+	defer tx.Rollback()
+
+	query := "INSERT INTO venues (name, user_id) VALUES ($1, $2) RETURNING id"
+	err = tx.QueryRow(query, v.Name, v.UserID).Scan(&v.ID)
+	if err != nil {
+		utils.Logger.Error("Failed to save venue to database", "name", v.Name, "error", err)
+		return err
+	}
+
+	for i := range v.Sections {
+		section := &v.Sections[i]
+		query := "INSERT INTO venue_sections (venue_id, name, position) VALUES ($1, $2, $3) RETURNING id"
+		err = tx.QueryRow(query, v.ID, section.Name, i).Scan(&section.ID)
+		if isUniqueViolation(err) {
+			return ErrInvalidVenue
+		}
+		if err != nil {
+			utils.Logger.Error("Failed to save venue section", "venue_id", v.ID, "error", err)
+			return err
+		}
+
+		for j := range section.Rows {
+			row := &section.Rows[j]
+			query := "INSERT INTO venue_rows (section_id, label, position) VALUES ($1, $2, $3) RETURNING id"
+			err = tx.QueryRow(query, section.ID, row.Label, j).Scan(&row.ID)
+			if isUniqueViolation(err) {
+				return ErrInvalidVenue
+			}
+			if err != nil {
+				utils.Logger.Error("Failed to save venue row", "venue_id", v.ID, "error", err)
+				return err
+			}
+
+			for k := range row.Seats {
+				seat := &row.Seats[k]
+				query := "INSERT INTO venue_seats (row_id, label, position) VALUES ($1, $2, $3) RETURNING id"
+				err = tx.QueryRow(query, row.ID, seat.Label, k).Scan(&seat.ID)
+				if isUniqueViolation(err) {
+					return ErrInvalidVenue
+				}
+				if err != nil {
+					utils.Logger.Error("Failed to save venue seat", "venue_id", v.ID, "error", err)
+					return err
+				}
+			}
+		}
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		utils.Logger.Error("Failed to commit venue save", "venue_id", v.ID, "error", err)
+		return err
+	}
+	utils.Logger.Debug("Venue saved to database", "venue_id", v.ID, "name", v.Name)
+	return nil
+}
+
+// Delete removes the venue and its layout. Venues used by an event cannot
+// be deleted.
+func (v *Venue) Delete() error {
+	_, err := db.DB.Exec("DELETE FROM venues WHERE id = $1", v.ID)
+	if isForeignKeyViolation(err) {
+		return ErrVenueInUse
+	}
+	if err != nil {
+		utils.Logger.Error("Failed to delete venue from database", "venue_id", v.ID, "error", err)
+		return err
+	}
+	utils.Logger.Debug("Venue deleted from database", "venue_id", v.ID)
+	return nil
+}
+
+func GetVenues() ([]VenueSummary, error) {
+	query := `
+        SELECT v.id, v.name, v.user_id, COUNT(s.id)
+        FROM venues v
+        LEFT JOIN venue_sections sec ON sec.venue_id = v.id
+        LEFT JOIN venue_rows r ON r.section_id = sec.id
+        LEFT JOIN venue_seats s ON s.row_id = r.id
+        GROUP BY v.id
+        ORDER BY v.name, v.id
+    `
+	rows, err := db.DB.Query(query)
+	if err != nil {
+		utils.Logger.Error("Failed to query venues", "error", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	venues := []VenueSummary{}
+	for rows.Next() {
+		var v VenueSummary
+		var userID sql.NullInt64
+		err := rows.Scan(&v.ID, &v.Name, &userID, &v.Seats)
+		if err != nil {
+			utils.Logger.Error("Failed to scan venue row", "error", err)
+			return nil, err
+		}
+		if userID.Valid {
+			v.UserID = &userID.Int64
+		}
+		venues = append(venues, v)
+	}
+
+	utils.Logger.Debug("Retrieved venues", "count", len(venues))
+	return venues, nil
+}
+
+// GetVenueByID loads a venue with its full layout. It returns
+// ErrUnknownVenue if there is no such venue.
+func GetVenueByID(id int64) (*Venue, error) {
+	var v Venue
+	var userID sql.NullInt64
+	err := db.DB.QueryRow("SELECT id, name, user_id FROM venues WHERE id = $1", id).Scan(&v.ID, &v.Name, &userID)
+	if err == sql.ErrNoRows {
+		return nil, ErrUnknownVenue
+	}
+	if err != nil {
+		utils.Logger.Error("Failed to get venue by ID", "venue_id", id, "error", err)
+		return nil, err
+	}
+	if userID.Valid {
+		v.UserID = &userID.Int64
+	}
+
+	query := `
+        SELECT sec.id, sec.name, r.id, r.label, s.id, s.label
+        FROM venue_sections sec
+        JOIN venue_rows r ON r.section_id = sec.id
+        JOIN venue_seats s ON s.row_id = r.id
+        WHERE sec.venue_id = $1
+        ORDER BY sec.position, r.position, s.position
+    `
+	rows, err := db.DB.Query(query, id)
+	if err != nil {
+		utils.Logger.Error("Failed to query venue layout", "venue_id", id, "error", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	v.Sections = []VenueSection{}
+	for rows.Next() {
+		var section VenueSection
+		var row VenueRow
+		var seat VenueSeat
+		err := rows.Scan(&section.ID, &section.Name, &row.ID, &row.Label, &seat.ID, &seat.Label)
+		if err != nil {
+			utils.Logger.Error("Failed to scan venue layout row", "venue_id", id, "error", err)
+			return nil, err
+		}
+
+		// Rows arrive in layout order, so a new section or row starts
+		// whenever its ID changes
+		if n := len(v.Sections); n == 0 || v.Sections[n-1].ID != section.ID {
+			v.Sections = append(v.Sections, section)
+		}
+		current := &v.Sections[len(v.Sections)-1]
+		if n := len(current.Rows); n == 0 || current.Rows[n-1].ID != row.ID {
+			current.Rows = append(current.Rows, row)
+		}
+		currentRow := &current.Rows[len(current.Rows)-1]
+		currentRow.Seats = append(currentRow.Seats, seat)
+	}
+
+	utils.Logger.Debug("Retrieved venue by ID", "venue_id", id, "name", v.Name)
+	return &v, nil
+}
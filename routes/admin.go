@@ -0,0 +1,138 @@
+package routes
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"example.com/event-booking-api/models"
+	"example.com/event-booking-api/utils"
+	"github.com/gin-gonic/gin"
+)
+
+func getDeletedEventsHandler(c *gin.Context) {
+	events, err := models.GetDeletedEvents()
+	if err != nil {
+		utils.Logger.Error("Failed to retrieve deleted events", "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to retrieve deleted events",
+		})
+		return
+	}
+	utils.Logger.Debug("Retrieved deleted events", "count", len(events))
+	c.JSON(http.StatusOK, events)
+}
+
+func restoreEventHandler(c *gin.Context) {
+	eventId, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		utils.Logger.Warn("Invalid event ID parameter", "id", c.Param("id"), "error", err)
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid event ID",
+		})
+		return
+	}
+
+	err = models.RestoreEvent(eventId)
+	if errors.Is(err, models.ErrNotDeleted) {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error": "Deleted event not found",
+		})
+		return
+	}
+	if errors.Is(err, models.ErrOwnerDeleted) {
+		utils.Logger.Warn("Restore of event with deleted owner", "event_id", eventId)
+		c.JSON(http.StatusConflict, gin.H{
+			"error": "The event's owner is deleted; restore the user first",
+		})
+		return
+	}
+	if err != nil {
+		utils.Logger.Error("Failed to restore event", "event_id", eventId, "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to restore event",
+		})
+		return
+	}
+
+	event, err := models.GetEventByID(eventId)
+	if err != nil {
+		utils.Logger.Error("Failed to retrieve restored event", "event_id", eventId, "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to retrieve event",
+		})
+		return
+	}
+
+	utils.Logger.Info("Event restored successfully",
+		"event_id", eventId,
+		"admin_id", c.GetInt64("userID"))
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Event restored successfully",
+		"event":   event,
+	})
+}
+
+func getDeletedUsersHandler(c *gin.Context) {
+	users, err := models.GetDeletedUsers()
+	if err != nil {
+		utils.Logger.Error("Failed to retrieve deleted users", "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to retrieve deleted users",
+		})
+		return
+	}
+	utils.Logger.Debug("Retrieved deleted users", "count", len(users))
+	c.JSON(http.StatusOK, users)
+}
+
+func restoreUserHandler(c *gin.Context) {
+	userID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		utils.Logger.Warn("Invalid user ID parameter", "id", c.Param("id"), "error", err)
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid user ID",
+		})
+		return
+	}
+
+	err = models.RestoreUser(userID)
+	if errors.Is(err, models.ErrNotDeleted) {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error": "Deleted user not found",
+		})
+		return
+	}
+	if errors.Is(err, models.ErrEmailTaken) {
+		utils.Logger.Warn("Restore of user whose email was reused", "user_id", userID)
+		c.JSON(http.StatusConflict, gin.H{
+			"error": "Another user now has this email",
+		})
+		return
+	}
+	if err != nil {
+		utils.Logger.Error("Failed to restore user", "user_id", userID, "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to restore user",
+		})
+		return
+	}
+
+	user, err := models.GetUserByID(userID)
+	if err != nil {
+		utils.Logger.Error("Failed to retrieve restored user", "user_id", userID, "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to retrieve user",
+		})
+		return
+	}
+
+	utils.Logger.Info("User restored successfully",
+		"user_id", userID,
+		"email", user.Email,
+		"admin_id", c.GetInt64("userID"))
+	c.JSON(http.StatusOK, gin.H{
+		"message": "User restored successfully",
+		"user":    user.ToPublic(),
+	})
+}
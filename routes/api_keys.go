@@ -0,0 +1,241 @@
+package routes
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"example.com/event-booking-api/models"
+	"example.com/event-booking-api/utils"
+	"github.com/gin-gonic/gin"
+)
+
+func getAPIKeysHandler(c *gin.Context) {
+	listAPIKeys(c, c.GetInt64("userID"))
+}
+
+func createAPIKeyHandler(c *gin.Context) {
+	createAPIKey(c, c.GetInt64("userID"))
+}
+
+func deleteAPIKeyHandler(c *gin.Context) {
+	deleteAPIKey(c, c.GetInt64("userID"), c.Param("id"))
+}
+
+func getServiceAccountsHandler(c *gin.Context) {
+	accounts, err := models.GetServiceAccounts()
+	if err != nil {
+		utils.Logger.Error("Failed to retrieve service accounts", "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to retrieve service accounts",
+		})
+		return
+	}
+
+	sanitizedAccounts := make([]*models.PublicUser, len(accounts))
+	for i, account := range accounts {
+		sanitizedAccounts[i] = account.ToPublic()
+	}
+
+	utils.Logger.Debug("Retrieved service accounts", "count", len(accounts))
+	c.JSON(http.StatusOK, sanitizedAccounts)
+}
+
+func createServiceAccountHandler(c *gin.Context) {
+	var payload struct {
+		Email string `json:"email" binding:"required,email"`
+		Role  string `json:"role" binding:"required"`
+	}
+	err := c.ShouldBindJSON(&payload)
+	if err != nil {
+		utils.Logger.Warn("Invalid service account payload", "error", err)
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid request payload",
+		})
+		return
+	}
+
+	account, err := models.CreateServiceAccount(payload.Email, payload.Role)
+	if errors.Is(err, models.ErrUnknownRole) {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Role does not exist",
+		})
+		return
+	}
+	if errors.Is(err, models.ErrEmailTaken) {
+		utils.Logger.Warn("Service account with email in use", "email", payload.Email)
+		c.JSON(http.StatusConflict, gin.H{
+			"error": "User with this email already exists",
+		})
+		return
+	}
+	if err != nil {
+		utils.Logger.Error("Failed to create service account", "email", payload.Email, "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to create service account",
+		})
+		return
+	}
+
+	utils.Logger.Info("Service account created successfully",
+		"user_id", account.ID,
+		"email", account.Email,
+		"role", account.Role,
+		"created_by", c.GetInt64("userID"))
+	c.JSON(http.StatusCreated, gin.H{
+		"message":         "Service account created successfully",
+		"service_account": account.ToPublic(),
+	})
+}
+
+func getServiceAccountAPIKeysHandler(c *gin.Context) {
+	accountID, ok := serviceAccountParam(c)
+	if !ok {
+		return
+	}
+	listAPIKeys(c, accountID)
+}
+
+func createServiceAccountAPIKeyHandler(c *gin.Context) {
+	accountID, ok := serviceAccountParam(c)
+	if !ok {
+		return
+	}
+	createAPIKey(c, accountID)
+}
+
+func deleteServiceAccountAPIKeyHandler(c *gin.Context) {
+	accountID, ok := serviceAccountParam(c)
+	if !ok {
+		return
+	}
+	deleteAPIKey(c, accountID, c.Param("keyId"))
+}
+
+// serviceAccountParam resolves the :id of a service account route. It
+// responds and returns false if there is no such service account.
+func serviceAccountParam(c *gin.Context) (int64, bool) {
+	accountID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		utils.Logger.Warn("Invalid service account ID parameter", "id", c.Param("id"), "error", err)
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid service account ID",
+		})
+		return 0, false
+	}
+
+	_, err = models.GetServiceAccount(accountID)
+	if errors.Is(err, models.ErrUnknownServiceAccount) {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error": "Service account not found",
+		})
+		return 0, false
+	}
+	if err != nil {
+		utils.Logger.Error("Failed to retrieve service account", "user_id", accountID, "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to retrieve service account",
+		})
+		return 0, false
+	}
+	return accountID, true
+}
+
+func listAPIKeys(c *gin.Context, ownerID int64) {
+	keys, err := models.GetAPIKeysForUser(ownerID)
+	if err != nil {
+		utils.Logger.Error("Failed to retrieve API keys", "user_id", ownerID, "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to retrieve API keys",
+		})
+		return
+	}
+	utils.Logger.Debug("Retrieved API keys", "user_id", ownerID, "count", len(keys))
+	c.JSON(http.StatusOK, keys)
+}
+
+func createAPIKey(c *gin.Context, ownerID int64) {
+	key := models.APIKey{}
+	err := c.ShouldBindJSON(&key)
+	if err != nil {
+		utils.Logger.Warn("Invalid API key payload", "user_id", ownerID, "error", err)
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid request payload",
+		})
+		return
+	}
+	key.UserID = ownerID
+
+	secret, err := key.Save()
+	if errors.Is(err, models.ErrUnknownScope) {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":  "Unknown scope",
+			"scopes": models.Scopes,
+		})
+		return
+	}
+	if errors.Is(err, models.ErrInvalidAPIKeyExpiry) {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "API key expiry must be in the future",
+		})
+		return
+	}
+	if err != nil {
+		utils.Logger.Error("Failed to create API key", "user_id", ownerID, "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to create API key",
+		})
+		return
+	}
+
+	utils.Logger.Info("API key created successfully",
+		"api_key_id", key.ID,
+		"user_id", ownerID,
+		"scopes", key.Scopes,
+		"created_by", c.GetInt64("userID"))
+	c.JSON(http.StatusCreated, gin.H{
+		"message": "API key created successfully, store it now as it will not be shown again",
+		"key":     secret,
+		"api_key": key,
+	})
+}
+
+func deleteAPIKey(c *gin.Context, ownerID int64, keyParam string) {
+	keyID, err := strconv.ParseInt(keyParam, 10, 64)
+	if err != nil {
+		utils.Logger.Warn("Invalid API key ID parameter", "id", keyParam, "error", err)
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid API key ID",
+		})
+		return
+	}
+
+	key, err := models.GetAPIKey(ownerID, keyID)
+	if errors.Is(err, models.ErrUnknownAPIKey) {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error": "API key not found",
+		})
+		return
+	}
+	if err != nil {
+		utils.Logger.Error("Failed to retrieve API key for deletion", "api_key_id", keyID, "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to retrieve API key",
+		})
+		return
+	}
+
+	err = key.Delete()
+	if err != nil {
+		utils.Logger.Error("Failed to delete API key", "api_key_id", keyID, "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to delete API key",
+		})
+		return
+	}
+
+	utils.Logger.Info("API key revoked", "api_key_id", keyID, "user_id", ownerID, "revoked_by", c.GetInt64("userID"))
+	c.JSON(http.StatusOK, gin.H{
+		"message": "API key revoked successfully",
+	})
+}
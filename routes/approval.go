@@ -0,0 +1,133 @@
+package routes
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"example.com/event-booking-api/middlewares"
+	"example.com/event-booking-api/models"
+	"example.com/event-booking-api/utils"
+	"github.com/gin-gonic/gin"
+)
+
+func approveRegistrationHandler(c *gin.Context) {
+	reviewRegistration(c, true)
+}
+
+func rejectRegistrationHandler(c *gin.Context) {
+	reviewRegistration(c, false)
+}
+
+// reviewRegistration approves or rejects a pending application. Only the
+// event owner or users allowed to review any application may do so.
+func reviewRegistration(c *gin.Context, approve bool) {
+	eventId, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		utils.Logger.Warn("Invalid event ID parameter", "id", c.Param("id"), "error", err)
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid event ID",
+		})
+		return
+	}
+
+	registrationId, err := strconv.ParseInt(c.Param("registrationId"), 10, 64)
+	if err != nil {
+		utils.Logger.Warn("Invalid registration ID parameter", "id", c.Param("registrationId"), "error", err)
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid registration ID",
+		})
+		return
+	}
+
+	event, err := models.GetEventByID(eventId)
+	if err != nil {
+		utils.Logger.Error("Failed to retrieve event for registration review", "event_id", eventId, "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to retrieve event",
+		})
+		return
+	}
+
+	if !canSeeEvent(c, event) {
+		return
+	}
+
+	userID := c.GetInt64("userID")
+	role := c.GetString("role")
+
+	if event.UserID != userID && !middlewares.HasPermission(c, models.PermRegistrationsReviewAny) {
+		utils.Logger.Warn("Unauthorized registration review attempt",
+			"event_id", eventId,
+			"event_owner", event.UserID,
+			"user_id", userID,
+			"role", role)
+		c.JSON(http.StatusForbidden, gin.H{
+			"error": "You are not authorized to review registrations for this event",
+		})
+		return
+	}
+
+	var registration *models.Registration
+	if approve {
+		registration, err = event.ApproveRegistration(registrationId, userID)
+	} else {
+		registration, err = event.RejectRegistration(registrationId, userID)
+	}
+	if errors.Is(err, models.ErrRegistrationMissing) {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error": "Registration not found",
+		})
+		return
+	}
+	if errors.Is(err, models.ErrRegistrationNotPending) {
+		c.JSON(http.StatusConflict, gin.H{
+			"error": "Registration is not awaiting review",
+		})
+		return
+	}
+	if err != nil {
+		utils.Logger.Error("Failed to review registration",
+			"event_id", eventId,
+			"registration_id", registrationId,
+			"approve", approve,
+			"error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to review registration",
+		})
+		return
+	}
+
+	utils.Logger.Info("Registration reviewed",
+		"event_id", eventId,
+		"registration_id", registrationId,
+		"status", registration.Status,
+		"user_id", userID)
+
+	message := "Registration rejected"
+	switch registration.Status {
+	case models.RegistrationConfirmed:
+		message = "Registration approved"
+	case models.RegistrationWaitlisted:
+		message = "Registration approved, the applicant has been added to the waitlist"
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"message":      message,
+		"registration": registration,
+	})
+}
+
+func getMyApplicationsHandler(c *gin.Context) {
+	userID := c.GetInt64("userID")
+
+	applications, err := models.GetApplicationsForUser(userID)
+	if err != nil {
+		utils.Logger.Error("Failed to retrieve user's applications", "user_id", userID, "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to retrieve applications",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, applications)
+}
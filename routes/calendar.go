@@ -0,0 +1,235 @@
+package routes
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"example.com/event-booking-api/middlewares"
+	"example.com/event-booking-api/models"
+	"example.com/event-booking-api/utils"
+	"github.com/gin-gonic/gin"
+)
+
+const calendarContentType = "text/calendar; charset=utf-8"
+
+func getEventICSHandler(c *gin.Context) {
+	eventId, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		utils.Logger.Warn("Invalid event ID parameter", "id", c.Param("id"), "error", err)
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid event ID",
+		})
+		return
+	}
+
+	event, err := models.GetEventByID(eventId)
+	if err != nil {
+		utils.Logger.Error("Failed to retrieve event for iCalendar export", "event_id", eventId, "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to retrieve event",
+		})
+		return
+	}
+
+	if event.IsDraft() {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error": "Event not found",
+		})
+		return
+	}
+
+	overrides, err := models.GetOccurrenceOverrides([]int64{event.ID})
+	if err != nil {
+		utils.Logger.Error("Failed to retrieve occurrence overrides", "event_id", eventId, "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to retrieve event",
+		})
+		return
+	}
+
+	c.Header("Content-Type", calendarContentType)
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="event-%d.ics"`, event.ID))
+	c.Status(http.StatusOK)
+
+	err = utils.WriteCalendar(c.Writer, event.Title, seriesCalendarEvents(*event, overrides[event.ID]))
+	if err != nil {
+		utils.Logger.Error("Failed to write iCalendar export", "event_id", eventId, "error", err)
+		return
+	}
+	utils.Logger.Debug("Exported event as iCalendar", "event_id", eventId)
+}
+
+func rotateCalendarTokenHandler(c *gin.Context) {
+	userID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		utils.Logger.Warn("Invalid user ID parameter", "id", c.Param("id"), "error", err)
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid user ID",
+		})
+		return
+	}
+
+	tokenUserID := c.GetInt64("userID")
+	role := c.GetString("role")
+	if tokenUserID != userID && !middlewares.HasPermission(c, models.PermUsersUpdateAny) {
+		utils.Logger.Warn("Unauthorized calendar token rotation attempt",
+			"target_user_id", userID,
+			"token_user_id", tokenUserID,
+			"role", role)
+		c.JSON(http.StatusForbidden, gin.H{
+			"error": "You are not authorized to manage this user's calendar feed",
+		})
+		return
+	}
+
+	user, err := models.GetUserByID(userID)
+	if err != nil {
+		utils.Logger.Error("Failed to retrieve user for calendar token", "user_id", userID, "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to retrieve user",
+		})
+		return
+	}
+
+	token, err := user.RotateCalendarToken()
+	if err != nil {
+		utils.Logger.Error("Failed to rotate calendar token", "user_id", userID, "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to create calendar feed",
+		})
+		return
+	}
+
+	utils.Logger.Info("Calendar feed token rotated", "user_id", userID)
+	c.JSON(http.StatusCreated, gin.H{
+		"message":  "Calendar feed created. Previous feed URLs no longer work",
+		"token":    token,
+		"feed_url": "/calendar/" + token + ".ics",
+	})
+}
+
+func getCalendarFeedHandler(c *gin.Context) {
+	// Calendar apps like the URL to end in .ics
+	token := strings.TrimSuffix(c.Param("token"), ".ics")
+
+	user, err := models.GetUserByCalendarToken(token)
+	if err != nil {
+		utils.Logger.Warn("Calendar feed requested with unknown token")
+		c.JSON(http.StatusNotFound, gin.H{
+			"error": "Calendar feed not found",
+		})
+		return
+	}
+
+	registered, err := models.GetConfirmedEventsForUser(user.ID)
+	if err != nil {
+		utils.Logger.Error("Failed to retrieve registered events for feed", "user_id", user.ID, "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to retrieve calendar feed",
+		})
+		return
+	}
+
+	eventIDs := []int64{}
+	for _, r := range registered {
+		if r.Event.IsRecurring() {
+			eventIDs = append(eventIDs, r.Event.ID)
+		}
+	}
+	overrides, err := models.GetOccurrenceOverrides(eventIDs)
+	if err != nil {
+		utils.Logger.Error("Failed to retrieve occurrence overrides for feed", "user_id", user.ID, "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to retrieve calendar feed",
+		})
+		return
+	}
+
+	events := []utils.CalendarEvent{}
+	for _, r := range registered {
+		if r.OccurrenceDate == nil {
+			events = append(events, seriesCalendarEvents(r.Event, overrides[r.Event.ID])...)
+			continue
+		}
+		occurrence, ok := occurrenceCalendarEvent(r.Event, *r.OccurrenceDate, overrides[r.Event.ID])
+		if ok {
+			// Without the series in the feed, each occurrence stands alone
+			occurrence.UID = fmt.Sprintf("event-%d-%d@event-booking-api", r.Event.ID, r.OccurrenceDate.Unix())
+			events = append(events, occurrence)
+		}
+	}
+
+	c.Header("Content-Type", calendarContentType)
+	c.Status(http.StatusOK)
+
+	err = utils.WriteCalendar(c.Writer, "My events", events)
+	if err != nil {
+		utils.Logger.Error("Failed to write calendar feed", "user_id", user.ID, "error", err)
+		return
+	}
+	utils.Logger.Debug("Served calendar feed", "user_id", user.ID, "count", len(events))
+}
+
+func calendarEventUID(eventID int64) string {
+	return fmt.Sprintf("event-%d@event-booking-api", eventID)
+}
+
+// seriesCalendarEvents renders an event, and for recurring events one extra
+// VEVENT per modified occurrence.
+func seriesCalendarEvents(e models.Event, overrides []models.OccurrenceOverride) []utils.CalendarEvent {
+	main := utils.CalendarEvent{
+		UID:         calendarEventUID(e.ID),
+		Summary:     e.Title,
+		Description: e.Description,
+		Location:    e.Location,
+		Start:       e.Date,
+		Status:      calendarStatus(e),
+	}
+	if !e.IsRecurring() {
+		return []utils.CalendarEvent{main}
+	}
+
+	main.RRule = *e.RecurrenceRule
+	main.ExDates = e.RecurrenceExDates
+	events := []utils.CalendarEvent{main}
+
+	for _, o := range overrides {
+		occurrence, ok := occurrenceCalendarEvent(e, o.OccurrenceDate, overrides)
+		if !ok {
+			continue
+		}
+		recurrenceID := o.OccurrenceDate
+		occurrence.RecurrenceID = &recurrenceID
+		events = append(events, occurrence)
+	}
+	return events
+}
+
+// occurrenceCalendarEvent renders a single occurrence of a recurring event
+// with its override applied. It reports false if the date is no longer an
+// occurrence, e.g. because it was cancelled.
+func occurrenceCalendarEvent(e models.Event, date time.Time, overrides []models.OccurrenceOverride) (utils.CalendarEvent, bool) {
+	occurrences, err := e.ExpandOccurrences(date, date.Add(time.Second), overrides)
+	if err != nil || len(occurrences) == 0 {
+		return utils.CalendarEvent{}, false
+	}
+	o := occurrences[0]
+	return utils.CalendarEvent{
+		UID:         calendarEventUID(e.ID),
+		Summary:     o.Title,
+		Description: o.Description,
+		Location:    o.Location,
+		Start:       o.Date,
+		Status:      calendarStatus(e),
+	}, true
+}
+
+func calendarStatus(e models.Event) string {
+	if e.Status == models.EventCancelled {
+		return "CANCELLED"
+	}
+	return "CONFIRMED"
+}
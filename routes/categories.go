@@ -0,0 +1,157 @@
+package routes
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"example.com/event-booking-api/models"
+	"example.com/event-booking-api/utils"
+	"github.com/gin-gonic/gin"
+)
+
+func getCategoriesHandler(c *gin.Context) {
+	categories, err := models.GetAllCategories()
+	if err != nil {
+		utils.Logger.Error("Failed to retrieve categories", "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to retrieve categories",
+		})
+		return
+	}
+	utils.Logger.Debug("Retrieved categories", "count", len(categories))
+	c.JSON(http.StatusOK, categories)
+}
+
+func createCategoryHandler(c *gin.Context) {
+	category := models.Category{}
+	err := c.ShouldBindJSON(&category)
+	if err != nil {
+		utils.Logger.Warn("Invalid category creation payload", "error", err)
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid request payload",
+		})
+		return
+	}
+
+	err = category.Save()
+	if errors.Is(err, models.ErrDuplicateCategory) {
+		utils.Logger.Warn("Duplicate category creation attempt", "name", category.Name)
+		c.JSON(http.StatusConflict, gin.H{
+			"error": "Category with this name already exists",
+		})
+		return
+	}
+	if err != nil {
+		utils.Logger.Error("Failed to create category", "name", category.Name, "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to create category",
+		})
+		return
+	}
+
+	utils.Logger.Info("Category created successfully", "category_id", category.ID, "name", category.Name)
+	c.JSON(http.StatusCreated, gin.H{
+		"message":  "Category created successfully",
+		"category": category,
+	})
+}
+
+func updateCategoryHandler(c *gin.Context) {
+	categoryID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		utils.Logger.Warn("Invalid category ID parameter", "id", c.Param("id"), "error", err)
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid category ID",
+		})
+		return
+	}
+
+	category, err := models.GetCategoryByID(categoryID)
+	if err != nil {
+		utils.Logger.Error("Failed to retrieve category for update", "category_id", categoryID, "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to retrieve category",
+		})
+		return
+	}
+
+	err = c.ShouldBindJSON(category)
+	if err != nil {
+		utils.Logger.Warn("Invalid category update payload", "category_id", categoryID, "error", err)
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid request payload",
+		})
+		return
+	}
+	category.ID = categoryID
+
+	err = category.Update()
+	if errors.Is(err, models.ErrDuplicateCategory) {
+		utils.Logger.Warn("Duplicate category name on update", "category_id", categoryID, "name", category.Name)
+		c.JSON(http.StatusConflict, gin.H{
+			"error": "Category with this name already exists",
+		})
+		return
+	}
+	if err != nil {
+		utils.Logger.Error("Failed to update category", "category_id", categoryID, "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to update category",
+		})
+		return
+	}
+
+	utils.Logger.Info("Category updated successfully", "category_id", categoryID, "name", category.Name)
+	c.JSON(http.StatusOK, gin.H{
+		"message":  "Category updated successfully",
+		"category": category,
+	})
+}
+
+func deleteCategoryHandler(c *gin.Context) {
+	categoryID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		utils.Logger.Warn("Invalid category ID parameter", "id", c.Param("id"), "error", err)
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid category ID",
+		})
+		return
+	}
+
+	category, err := models.GetCategoryByID(categoryID)
+	if err != nil {
+		utils.Logger.Error("Failed to retrieve category for deletion", "category_id", categoryID, "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to retrieve category",
+		})
+		return
+	}
+
+	err = category.Delete()
+	if err != nil {
+		utils.Logger.Error("Failed to delete category", "category_id", categoryID, "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to delete category",
+		})
+		return
+	}
+
+	utils.Logger.Info("Category deleted successfully", "category_id", categoryID, "name", category.Name)
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Category deleted successfully",
+	})
+}
+
+func getTagsHandler(c *gin.Context) {
+	tags, err := models.GetTagCounts()
+	if err != nil {
+		utils.Logger.Error("Failed to retrieve tags", "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to retrieve tags",
+		})
+		return
+	}
+	utils.Logger.Debug("Retrieved tags", "count", len(tags))
+	c.JSON(http.StatusOK, tags)
+}
@@ -0,0 +1,215 @@
+package routes
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"example.com/event-booking-api/middlewares"
+	"example.com/event-booking-api/models"
+	"example.com/event-booking-api/utils"
+	"github.com/gin-gonic/gin"
+	"github.com/skip2/go-qrcode"
+)
+
+const ticketQRSize = 256
+
+func getRegistrationQRHandler(c *gin.Context) {
+	registrationId, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		utils.Logger.Warn("Invalid registration ID parameter", "id", c.Param("id"), "error", err)
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid registration ID",
+		})
+		return
+	}
+
+	registration, err := models.GetRegistrationByID(registrationId)
+	if errors.Is(err, models.ErrRegistrationMissing) {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error": "Registration not found",
+		})
+		return
+	}
+	if err != nil {
+		utils.Logger.Error("Failed to retrieve registration for QR code", "registration_id", registrationId, "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to retrieve registration",
+		})
+		return
+	}
+
+	userID := c.GetInt64("userID")
+	role := c.GetString("role")
+
+	if registration.UserID != userID && !middlewares.HasPermission(c, models.PermRegistrationsReadAny) {
+		utils.Logger.Warn("Unauthorized ticket QR code request",
+			"registration_id", registrationId,
+			"registration_owner", registration.UserID,
+			"user_id", userID,
+			"role", role)
+		c.JSON(http.StatusForbidden, gin.H{
+			"error": "You are not authorized to view this ticket",
+		})
+		return
+	}
+
+	if registration.Status != models.RegistrationConfirmed {
+		c.JSON(http.StatusConflict, gin.H{
+			"error": "Only confirmed registrations have a ticket",
+		})
+		return
+	}
+
+	code, err := utils.GenerateTicketCode(registration.ID, registration.EventID)
+	if err != nil {
+		utils.Logger.Error("Failed to generate ticket code", "registration_id", registrationId, "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to generate ticket",
+		})
+		return
+	}
+
+	png, err := qrcode.Encode(code, qrcode.Medium, ticketQRSize)
+	if err != nil {
+		utils.Logger.Error("Failed to render ticket QR code", "registration_id", registrationId, "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to generate ticket",
+		})
+		return
+	}
+
+	utils.Logger.Debug("Rendered ticket QR code", "registration_id", registrationId, "user_id", userID)
+	c.Header("Cache-Control", "private, no-store")
+	c.Data(http.StatusOK, "image/png", png)
+}
+
+func checkInHandler(c *gin.Context) {
+	eventId, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		utils.Logger.Warn("Invalid event ID parameter", "id", c.Param("id"), "error", err)
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid event ID",
+		})
+		return
+	}
+
+	event, err := models.GetEventByID(eventId)
+	if err != nil {
+		utils.Logger.Error("Failed to retrieve event for check-in", "event_id", eventId, "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to retrieve event",
+		})
+		return
+	}
+
+	if !canSeeEvent(c, event) {
+		return
+	}
+
+	userID := c.GetInt64("userID")
+	role := c.GetString("role")
+
+	if event.UserID != userID && !middlewares.HasPermission(c, models.PermRegistrationsCheckInAny) {
+		utils.Logger.Warn("Unauthorized check-in attempt",
+			"event_id", eventId,
+			"event_owner", event.UserID,
+			"user_id", userID,
+			"role", role)
+		c.JSON(http.StatusForbidden, gin.H{
+			"error": "You are not authorized to check in attendees for this event",
+		})
+		return
+	}
+
+	var payload struct {
+		Code           string     `json:"code" binding:"required"`
+		OccurrenceDate *time.Time `json:"occurrence_date"`
+	}
+
+	err = c.ShouldBindJSON(&payload)
+	if err != nil {
+		utils.Logger.Warn("Invalid check-in payload", "event_id", eventId, "error", err)
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid request payload",
+		})
+		return
+	}
+
+	registrationId, ticketEventId, err := utils.VerifyTicketCode(payload.Code)
+	if err != nil {
+		utils.Logger.Warn("Invalid ticket code scanned", "event_id", eventId, "error", err)
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid ticket code",
+		})
+		return
+	}
+	if ticketEventId != eventId {
+		utils.Logger.Warn("Ticket scanned at the wrong event",
+			"event_id", eventId,
+			"ticket_event_id", ticketEventId,
+			"registration_id", registrationId)
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Ticket is for a different event",
+		})
+		return
+	}
+
+	checkIn, err := event.CheckIn(registrationId, payload.OccurrenceDate, userID)
+	if errors.Is(err, models.ErrAlreadyCheckedIn) {
+		utils.Logger.Warn("Duplicate ticket scan",
+			"event_id", eventId,
+			"registration_id", registrationId,
+			"checked_in_at", checkIn.CheckedInAt)
+		c.JSON(http.StatusConflict, gin.H{
+			"error":    "Ticket has already been checked in",
+			"check_in": checkIn,
+		})
+		return
+	}
+	if errors.Is(err, models.ErrRegistrationMissing) || errors.Is(err, models.ErrInvalidTicket) {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error": "Registration not found",
+		})
+		return
+	}
+	if errors.Is(err, models.ErrTicketNotConfirmed) {
+		c.JSON(http.StatusConflict, gin.H{
+			"error": "Registration is not confirmed",
+		})
+		return
+	}
+	if errors.Is(err, models.ErrOccurrenceRequired) {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "occurrence_date is required for series tickets",
+		})
+		return
+	}
+	if errors.Is(err, models.ErrInvalidOccurrence) {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Ticket is not valid for this occurrence",
+		})
+		return
+	}
+	if err != nil {
+		utils.Logger.Error("Failed to check in attendee",
+			"event_id", eventId,
+			"registration_id", registrationId,
+			"error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to check in attendee",
+		})
+		return
+	}
+
+	utils.Logger.Info("Attendee checked in",
+		"event_id", eventId,
+		"registration_id", registrationId,
+		"attendee_id", checkIn.UserID,
+		"user_id", userID)
+	c.JSON(http.StatusOK, gin.H{
+		"message":  "Attendee checked in successfully",
+		"check_in": checkIn,
+	})
+}
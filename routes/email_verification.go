@@ -0,0 +1,116 @@
+package routes
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+
+	"example.com/event-booking-api/mailer"
+	"example.com/event-booking-api/models"
+	"example.com/event-booking-api/utils"
+	"github.com/gin-gonic/gin"
+)
+
+// sendVerificationEmail mails the user a link to verify their current email
+// address, unless one was sent too recently.
+func sendVerificationEmail(user *models.User) error {
+	err := user.ClaimVerificationEmail()
+	if err != nil {
+		return err
+	}
+
+	token, err := user.NewVerificationToken()
+	if err != nil {
+		return err
+	}
+
+	link := utils.GetEnvString("EMAIL_VERIFICATION_URL", "http://localhost:8080/verify-email") +
+		"?token=" + url.QueryEscape(token)
+	return mailer.Default.Send(mailer.Message{
+		To:      user.Email,
+		Subject: "Verify your email address",
+		Body: fmt.Sprintf("Welcome to Event Booking!\n\n"+
+			"To verify your email address, open this link within %d hours:\n\n%s\n\n"+
+			"Until then you cannot create events or register for them.\n",
+			int(models.EmailVerificationDuration.Hours()), link),
+	})
+}
+
+func verifyEmailHandler(c *gin.Context) {
+	var payload struct {
+		Token string `json:"token" binding:"required"`
+	}
+	err := c.ShouldBindJSON(&payload)
+	if err != nil {
+		utils.Logger.Warn("Invalid email verification payload", "error", err)
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid request payload",
+		})
+		return
+	}
+
+	user, err := models.VerifyEmail(payload.Token)
+	if errors.Is(err, models.ErrInvalidVerificationToken) {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Verification link is invalid or has expired",
+			"code":  "invalid_verification_token",
+		})
+		return
+	}
+	if err != nil {
+		utils.Logger.Error("Failed to verify email", "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to verify email",
+		})
+		return
+	}
+
+	utils.Logger.Info("Email verified", "user_id", user.ID, "email", user.Email)
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Email verified successfully",
+		"user":    user.ToPublic(),
+	})
+}
+
+func resendVerificationHandler(c *gin.Context) {
+	userID := c.GetInt64("userID")
+	user, err := models.GetUserByID(userID)
+	if err != nil {
+		utils.Logger.Error("Failed to retrieve user for verification email", "user_id", userID, "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to retrieve user",
+		})
+		return
+	}
+
+	err = sendVerificationEmail(user)
+	if errors.Is(err, models.ErrEmailAlreadyVerified) {
+		c.JSON(http.StatusConflict, gin.H{
+			"error": "Email is already verified",
+			"code":  "email_already_verified",
+		})
+		return
+	}
+	if errors.Is(err, models.ErrVerificationThrottled) {
+		utils.Logger.Warn("Verification email resend throttled", "user_id", userID)
+		c.Header("Retry-After", fmt.Sprint(int(models.VerificationResendInterval.Seconds())))
+		c.JSON(http.StatusTooManyRequests, gin.H{
+			"error": "A verification email was sent recently, please wait before asking again",
+			"code":  "verification_throttled",
+		})
+		return
+	}
+	if err != nil {
+		utils.Logger.Error("Failed to send verification email", "user_id", userID, "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to send verification email",
+		})
+		return
+	}
+
+	utils.Logger.Info("Verification email resent", "user_id", userID)
+	c.JSON(http.StatusAccepted, gin.H{
+		"message": "Verification email sent",
+	})
+}
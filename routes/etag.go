@@ -0,0 +1,53 @@
+package routes
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// etag formats a row version as a strong entity tag.
+func etag(version int) string {
+	return `"` + strconv.Itoa(version) + `"`
+}
+
+// checkIfMatch enforces the If-Match precondition against the current
+// version of a resource. Writes without the header are refused with 428 and
+// writes based on a stale version with 412. It reports whether the request
+// may proceed; otherwise the response has already been written.
+func checkIfMatch(c *gin.Context, version int) bool {
+	header := strings.TrimSpace(c.GetHeader("If-Match"))
+	if header == "" {
+		c.JSON(http.StatusPreconditionRequired, gin.H{
+			"error": "If-Match header is required",
+		})
+		return false
+	}
+	if header == "*" {
+		return true
+	}
+
+	// If-Match uses strong comparison, so weak tags never match
+	current := etag(version)
+	for _, tag := range strings.Split(header, ",") {
+		if strings.TrimSpace(tag) == current {
+			return true
+		}
+	}
+
+	c.Header("ETag", current)
+	c.JSON(http.StatusPreconditionFailed, gin.H{
+		"error": "Resource has been modified; fetch it again and retry",
+	})
+	return false
+}
+
+// versionConflict answers a write that lost a race with another update
+// after its precondition was checked.
+func versionConflict(c *gin.Context) {
+	c.JSON(http.StatusPreconditionFailed, gin.H{
+		"error": "Resource has been modified; fetch it again and retry",
+	})
+}
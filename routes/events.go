@@ -1,16 +1,33 @@
 package routes
 
 import (
+	"errors"
+	"io"
 	"net/http"
 	"strconv"
+	"time"
 
+	"example.com/event-booking-api/middlewares"
 	"example.com/event-booking-api/models"
 	"example.com/event-booking-api/utils"
 	"github.com/gin-gonic/gin"
 )
 
 func getEventsHandler(c *gin.Context) {
-	events, err := models.GetAllEvents()
+	filter := models.EventFilter{}
+	err := c.ShouldBindQuery(&filter)
+	if err != nil {
+		utils.Logger.Warn("Invalid event listing parameters", "error", err)
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid query parameters",
+		})
+		return
+	}
+
+	// The public listing only ever shows published events
+	filter.Status = models.EventPublished
+
+	page, err := models.ListEvents(filter)
 	if err != nil {
 		utils.Logger.Error("Failed to retrieve events", "error", err)
 		c.JSON(http.StatusInternalServerError, gin.H{
@@ -18,8 +35,59 @@ func getEventsHandler(c *gin.Context) {
 		})
 		return
 	}
-	utils.Logger.Debug("Retrieved events", "count", len(events))
-	c.JSON(http.StatusOK, events)
+	utils.Logger.Debug("Retrieved events", "count", len(page.Events), "total", page.Total)
+	c.JSON(http.StatusOK, page)
+}
+
+func getMyEventsHandler(c *gin.Context) {
+	filter := models.EventFilter{}
+	err := c.ShouldBindQuery(&filter)
+	if err != nil {
+		utils.Logger.Warn("Invalid event listing parameters", "error", err)
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid query parameters",
+		})
+		return
+	}
+
+	userID := c.GetInt64("userID")
+	filter.UserID = userID
+
+	page, err := models.ListEvents(filter)
+	if err != nil {
+		utils.Logger.Error("Failed to retrieve user's events", "user_id", userID, "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to retrieve events",
+		})
+		return
+	}
+	utils.Logger.Debug("Retrieved user's events", "user_id", userID, "count", len(page.Events), "total", page.Total)
+	c.JSON(http.StatusOK, page)
+}
+
+func searchEventsHandler(c *gin.Context) {
+	search := models.EventSearch{}
+	err := c.ShouldBindQuery(&search)
+	if err != nil {
+		utils.Logger.Warn("Invalid event search parameters", "error", err)
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid query parameters",
+		})
+		return
+	}
+
+	search.Status = models.EventPublished
+
+	page, err := models.SearchEvents(search)
+	if err != nil {
+		utils.Logger.Error("Failed to search events", "query", search.Query, "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to search events",
+		})
+		return
+	}
+	utils.Logger.Debug("Searched events", "query", search.Query, "count", len(page.Results), "total", page.Total)
+	c.JSON(http.StatusOK, page)
 }
 
 func getEventHandler(c *gin.Context) {
@@ -43,7 +111,24 @@ func getEventHandler(c *gin.Context) {
 		return
 	}
 
+	if !canSeeEvent(c, event) {
+		return
+	}
+
+	if event.IsRecurring() {
+		from := time.Now().UTC()
+		event.Occurrences, err = event.ListOccurrences(from, from.Add(models.DefaultOccurrenceWindow))
+		if err != nil {
+			utils.Logger.Error("Failed to expand event occurrences", "event_id", eventId, "error", err)
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error": "Failed to retrieve event",
+			})
+			return
+		}
+	}
+
 	utils.Logger.Debug("Retrieved event", "event_id", eventId, "title", event.Title)
+	c.Header("ETag", etag(event.Version))
 	c.JSON(http.StatusOK, event)
 }
 
@@ -64,6 +149,39 @@ func createEventHandler(c *gin.Context) {
 
 	err = event.Save()
 
+	if errors.Is(err, models.ErrInvalidRecurrenceRule) {
+		utils.Logger.Warn("Invalid recurrence rule", "user_id", userID, "error", err)
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+	if errors.Is(err, models.ErrInvalidRegistrationWindow) {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Registration must open before it closes",
+		})
+		return
+	}
+	if errors.Is(err, models.ErrSeatedApproval) {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Events with reserved seating cannot require approval",
+		})
+		return
+	}
+	if errors.Is(err, models.ErrUnknownVenue) {
+		utils.Logger.Warn("Event created with unknown venue", "venue_id", event.VenueID)
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Venue does not exist",
+		})
+		return
+	}
+	if errors.Is(err, models.ErrUnknownCategory) {
+		utils.Logger.Warn("Event created with unknown category", "user_id", userID, "category_id", event.CategoryID)
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Category does not exist",
+		})
+		return
+	}
 	if err != nil {
 		utils.Logger.Error("Failed to create event",
 			"user_id", userID,
@@ -104,10 +222,14 @@ func updateEventHandler(c *gin.Context) {
 		return
 	}
 
+	if !canSeeEvent(c, event) {
+		return
+	}
+
 	userID := c.GetInt64("userID")
 	role := c.GetString("role")
 
-	if event.UserID != userID && role != "admin" {
+	if event.UserID != userID && !middlewares.HasPermission(c, models.PermEventsUpdateAny) {
 		utils.Logger.Warn("Unauthorized event update attempt",
 			"event_id", eventId,
 			"event_owner", event.UserID,
@@ -119,6 +241,11 @@ func updateEventHandler(c *gin.Context) {
 		return
 	}
 
+	if !checkIfMatch(c, event.Version) {
+		utils.Logger.Warn("Event update precondition failed", "event_id", eventId, "if_match", c.GetHeader("If-Match"))
+		return
+	}
+
 	updatedEvent := models.Event{}
 	err = c.ShouldBindJSON(&updatedEvent)
 	if err != nil {
@@ -129,9 +256,58 @@ func updateEventHandler(c *gin.Context) {
 		return
 	}
 
+	// Ownership and status are not changed by an update
 	updatedEvent.ID = eventId
+	updatedEvent.UserID = event.UserID
+	updatedEvent.Status = event.Status
+	updatedEvent.Version = event.Version
 
 	err = updatedEvent.Update()
+	if errors.Is(err, models.ErrVersionConflict) {
+		utils.Logger.Warn("Concurrent event update rejected", "event_id", eventId, "user_id", userID)
+		versionConflict(c)
+		return
+	}
+	if errors.Is(err, models.ErrInvalidRecurrenceRule) {
+		utils.Logger.Warn("Invalid recurrence rule", "event_id", eventId, "error", err)
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+	if errors.Is(err, models.ErrInvalidRegistrationWindow) {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Registration must open before it closes",
+		})
+		return
+	}
+	if errors.Is(err, models.ErrSeatedApproval) {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Events with reserved seating cannot require approval",
+		})
+		return
+	}
+	if errors.Is(err, models.ErrUnknownVenue) {
+		utils.Logger.Warn("Event updated with unknown venue", "venue_id", updatedEvent.VenueID)
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Venue does not exist",
+		})
+		return
+	}
+	if errors.Is(err, models.ErrVenueLocked) {
+		c.JSON(http.StatusConflict, gin.H{
+			"error": "The venue cannot change while seats are held or booked",
+			"code":  "venue_locked",
+		})
+		return
+	}
+	if errors.Is(err, models.ErrUnknownCategory) {
+		utils.Logger.Warn("Event updated with unknown category", "event_id", eventId, "category_id", updatedEvent.CategoryID)
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Category does not exist",
+		})
+		return
+	}
 	if err != nil {
 		utils.Logger.Error("Failed to update event",
 			"event_id", eventId,
@@ -147,6 +323,7 @@ func updateEventHandler(c *gin.Context) {
 		"event_id", eventId,
 		"title", updatedEvent.Title,
 		"user_id", userID)
+	c.Header("ETag", etag(updatedEvent.Version))
 	c.JSON(http.StatusOK, gin.H{
 		"message": "Event updated successfully",
 		"event":   updatedEvent,
@@ -172,10 +349,14 @@ func deleteEventHandler(c *gin.Context) {
 		return
 	}
 
+	if !canSeeEvent(c, event) {
+		return
+	}
+
 	userID := c.GetInt64("userID")
 	role := c.GetString("role")
 
-	if event.UserID != userID && role != "admin" {
+	if event.UserID != userID && !middlewares.HasPermission(c, models.PermEventsDeleteAny) {
 		utils.Logger.Warn("Unauthorized event deletion attempt",
 			"event_id", eventId,
 			"event_owner", event.UserID,
@@ -227,8 +408,162 @@ func registerEventHandler(c *gin.Context) {
 		return
 	}
 
+	if !canSeeEvent(c, event) {
+		return
+	}
+
+	opts := models.RegistrationOptions{}
+	err = c.ShouldBindJSON(&opts)
+	if err != nil && !errors.Is(err, io.EOF) {
+		utils.Logger.Warn("Invalid registration payload", "event_id", eventId, "error", err)
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid request payload",
+		})
+		return
+	}
+
 	userID := c.GetInt64("userID")
-	err = event.Register(userID)
+	registration, err := event.Register(userID, opts)
+	if errors.Is(err, models.ErrRegistrationNotOpen) {
+		utils.Logger.Warn("Registration before the window opened", "event_id", eventId, "user_id", userID)
+		c.JSON(http.StatusConflict, gin.H{
+			"error":    "Registration for this event has not opened yet",
+			"code":     "registration_not_open",
+			"opens_at": event.RegistrationOpensAt,
+		})
+		return
+	}
+	if errors.Is(err, models.ErrRegistrationClosed) {
+		utils.Logger.Warn("Registration after the window closed", "event_id", eventId, "user_id", userID)
+		c.JSON(http.StatusConflict, gin.H{
+			"error":     "Registration for this event has closed",
+			"code":      "registration_closed",
+			"closes_at": event.RegistrationClosesAtFor(opts.OccurrenceDate),
+		})
+		return
+	}
+	if errors.Is(err, models.ErrEventNotOpen) {
+		utils.Logger.Warn("Registration for event that is not open",
+			"event_id", eventId,
+			"user_id", userID,
+			"status", event.Status)
+		c.JSON(http.StatusConflict, gin.H{
+			"error": "Event is not open for registration",
+			"code":  "event_not_open",
+		})
+		return
+	}
+	if errors.Is(err, models.ErrInvalidOccurrence) {
+		utils.Logger.Warn("Registration for invalid occurrence",
+			"event_id", eventId,
+			"user_id", userID,
+			"occurrence_date", opts.OccurrenceDate)
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Date is not an occurrence of this event",
+			"code":  "invalid_occurrence",
+		})
+		return
+	}
+	if errors.Is(err, models.ErrOccurrenceRequired) {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "occurrence_date is required for seated series",
+			"code":  "occurrence_required",
+		})
+		return
+	}
+	if errors.Is(err, models.ErrTicketTypeRequired) {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "A ticket type is required for this event",
+			"code":  "ticket_type_required",
+		})
+		return
+	}
+	if errors.Is(err, models.ErrUnknownTicketType) {
+		utils.Logger.Warn("Registration with unknown ticket type",
+			"event_id", eventId,
+			"user_id", userID,
+			"ticket_type_id", opts.TicketTypeID)
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Ticket type does not exist for this event",
+			"code":  "unknown_ticket_type",
+		})
+		return
+	}
+	if errors.Is(err, models.ErrTicketSalesClosed) {
+		c.JSON(http.StatusConflict, gin.H{
+			"error": "This ticket type is not on sale",
+			"code":  "ticket_sales_closed",
+		})
+		return
+	}
+	if errors.Is(err, models.ErrUnknownPromoCode) {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Promo code does not exist for this event",
+			"code":  "unknown_promo_code",
+		})
+		return
+	}
+	if errors.Is(err, models.ErrPromoCodeNotApplicable) {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Promo code does not apply to this ticket type",
+			"code":  "promo_code_not_applicable",
+		})
+		return
+	}
+	if errors.Is(err, models.ErrPromoCodeExpired) {
+		c.JSON(http.StatusConflict, gin.H{
+			"error": "Promo code has expired",
+			"code":  "promo_code_expired",
+		})
+		return
+	}
+	if errors.Is(err, models.ErrPromoCodeExhausted) || errors.Is(err, models.ErrPromoCodeUserLimit) {
+		utils.Logger.Warn("Registration with used up promo code",
+			"event_id", eventId,
+			"user_id", userID,
+			"error", err)
+		c.JSON(http.StatusConflict, gin.H{
+			"error": "Promo code has no uses left",
+			"code":  "promo_code_exhausted",
+		})
+		return
+	}
+	var answersErr *models.AnswersError
+	if errors.As(err, &answersErr) {
+		utils.Logger.Warn("Registration with invalid answers",
+			"event_id", eventId,
+			"user_id", userID,
+			"problems", len(answersErr.Problems))
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Some answers are missing or invalid",
+			"code":    "invalid_answers",
+			"details": answersErr.Problems,
+		})
+		return
+	}
+	if errors.Is(err, models.ErrSeatsNotHeld) {
+		utils.Logger.Warn("Registration without matching seat holds", "event_id", eventId, "user_id", userID)
+		c.JSON(http.StatusConflict, gin.H{
+			"error": "Hold one seat per member of the party before registering",
+			"code":  "seats_not_held",
+		})
+		return
+	}
+	if errors.Is(err, models.ErrSoldOut) {
+		c.JSON(http.StatusConflict, gin.H{
+			"error": "No seats are left for this event",
+			"code":  "sold_out",
+		})
+		return
+	}
+	if errors.Is(err, models.ErrAlreadyRegistered) {
+		utils.Logger.Warn("Duplicate event registration attempt", "event_id", eventId, "user_id", userID)
+		c.JSON(http.StatusConflict, gin.H{
+			"error": "You are already registered for this event",
+			"code":  "already_registered",
+		})
+		return
+	}
 	if err != nil {
 		utils.Logger.Error("Failed to register for event",
 			"event_id", eventId,
@@ -240,12 +575,45 @@ func registerEventHandler(c *gin.Context) {
 		return
 	}
 
+	if registration.Status == models.RegistrationPending {
+		utils.Logger.Info("User applied for event",
+			"event_id", eventId,
+			"event_title", event.Title,
+			"user_id", userID)
+		c.JSON(http.StatusCreated, gin.H{
+			"message":      "Your application has been received and is awaiting approval",
+			"registration": registration,
+		})
+		return
+	}
+
+	if registration.Status == models.RegistrationWaitlisted {
+		utils.Logger.Info("User added to event waitlist",
+			"event_id", eventId,
+			"event_title", event.Title,
+			"user_id", userID,
+			"position", *registration.WaitlistPosition)
+		c.JSON(http.StatusCreated, gin.H{
+			"message":           "Event is full, you have been added to the waitlist",
+			"waitlist_position": *registration.WaitlistPosition,
+			"registration":      registration,
+		})
+		return
+	}
+
+	registration.TicketCode, err = utils.GenerateTicketCode(registration.ID, eventId)
+	if err != nil {
+		// The ticket can still be fetched later from its QR endpoint
+		utils.Logger.Error("Failed to generate ticket code", "registration_id", registration.ID, "error", err)
+	}
+
 	utils.Logger.Info("User registered for event",
 		"event_id", eventId,
 		"event_title", event.Title,
 		"user_id", userID)
 	c.JSON(http.StatusCreated, gin.H{
-		"message": "Successfully registered for event",
+		"message":      "Successfully registered for event",
+		"registration": registration,
 	})
 }
 
@@ -268,8 +636,42 @@ func unregisterEventHandler(c *gin.Context) {
 		return
 	}
 
+	if !canSeeEvent(c, event) {
+		return
+	}
+
+	var occurrenceDate *time.Time
+	if value := c.Query("occurrence_date"); value != "" {
+		date, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			utils.Logger.Warn("Invalid occurrence date parameter", "occurrence_date", value, "error", err)
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "Invalid occurrence date",
+			})
+			return
+		}
+		occurrenceDate = &date
+	}
+
 	userID := c.GetInt64("userID")
-	err = event.Unregister(userID)
+	err = event.Unregister(userID, occurrenceDate)
+	if errors.Is(err, models.ErrUnregistrationClosed) {
+		utils.Logger.Warn("Unregistration after the deadline", "event_id", eventId, "user_id", userID)
+		c.JSON(http.StatusConflict, gin.H{
+			"error":     "The deadline for unregistering from this event has passed",
+			"code":      "unregistration_closed",
+			"closes_at": event.UnregistrationClosesAtFor(occurrenceDate),
+		})
+		return
+	}
+	if errors.Is(err, models.ErrNotRegistered) {
+		utils.Logger.Warn("Unregistration attempt without registration", "event_id", eventId, "user_id", userID)
+		c.JSON(http.StatusNotFound, gin.H{
+			"error": "You are not registered for this event",
+			"code":  "not_registered",
+		})
+		return
+	}
 	if err != nil {
 		utils.Logger.Error("Failed to unregister from event",
 			"event_id", eventId,
@@ -300,6 +702,34 @@ func getEventRegistrationsHandler(c *gin.Context) {
 		return
 	}
 
+	event, err := models.GetEventByID(eventId)
+	if err != nil {
+		utils.Logger.Error("Failed to retrieve event for registrations", "event_id", eventId, "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to retrieve event",
+		})
+		return
+	}
+
+	if !canSeeEvent(c, event) {
+		return
+	}
+
+	userID := c.GetInt64("userID")
+	role := c.GetString("role")
+
+	if event.UserID != userID && !middlewares.HasPermission(c, models.PermRegistrationsReadAny) {
+		utils.Logger.Warn("Unauthorized event registrations view attempt",
+			"event_id", eventId,
+			"event_owner", event.UserID,
+			"user_id", userID,
+			"role", role)
+		c.JSON(http.StatusForbidden, gin.H{
+			"error": "You are not authorized to view registrations for this event",
+		})
+		return
+	}
+
 	registrations, err := models.GetRegistrationsByEventIDWithUsers(eventId)
 	if err != nil {
 		utils.Logger.Error("Failed to retrieve event registrations", "event_id", eventId, "error", err)
@@ -309,8 +739,238 @@ func getEventRegistrationsHandler(c *gin.Context) {
 		return
 	}
 
+	sales, err := models.GetTicketSales(eventId)
+	if err != nil {
+		utils.Logger.Error("Failed to retrieve ticket sales", "event_id", eventId, "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to retrieve registrations",
+		})
+		return
+	}
+
 	utils.Logger.Debug("Retrieved event registrations",
 		"event_id", eventId,
 		"count", len(registrations))
-	c.JSON(http.StatusOK, registrations)
+	c.JSON(http.StatusOK, gin.H{
+		"registrations": registrations,
+		"sales":         sales,
+	})
+}
+
+func updateEventStatusHandler(c *gin.Context) {
+	eventId, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		utils.Logger.Warn("Invalid event ID parameter", "id", c.Param("id"), "error", err)
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid event ID",
+		})
+		return
+	}
+
+	event, err := models.GetEventByID(eventId)
+	if err != nil {
+		utils.Logger.Error("Failed to retrieve event for status update", "event_id", eventId, "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to retrieve event",
+		})
+		return
+	}
+
+	if !canSeeEvent(c, event) {
+		return
+	}
+
+	userID := c.GetInt64("userID")
+	role := c.GetString("role")
+
+	if event.UserID != userID && !middlewares.HasPermission(c, models.PermEventsUpdateAny) {
+		utils.Logger.Warn("Unauthorized event status update attempt",
+			"event_id", eventId,
+			"event_owner", event.UserID,
+			"user_id", userID,
+			"role", role)
+		c.JSON(http.StatusForbidden, gin.H{
+			"error": "You are not authorized to update this event",
+		})
+		return
+	}
+
+	var payload struct {
+		Status string `json:"status" binding:"required,oneof=published cancelled"`
+	}
+
+	err = c.ShouldBindJSON(&payload)
+	if err != nil {
+		utils.Logger.Warn("Invalid event status payload", "event_id", eventId, "error", err)
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid request payload",
+		})
+		return
+	}
+
+	oldStatus := event.Status
+	err = event.ChangeStatus(payload.Status)
+	if errors.Is(err, models.ErrInvalidStatusTransition) {
+		utils.Logger.Warn("Invalid event status transition",
+			"event_id", eventId,
+			"from", oldStatus,
+			"to", payload.Status)
+		c.JSON(http.StatusConflict, gin.H{
+			"error": "Cannot change event status from " + oldStatus + " to " + payload.Status,
+		})
+		return
+	}
+	if err != nil {
+		utils.Logger.Error("Failed to update event status",
+			"event_id", eventId,
+			"from", oldStatus,
+			"to", payload.Status,
+			"error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to update event status",
+		})
+		return
+	}
+
+	utils.Logger.Info("Event status updated successfully",
+		"event_id", eventId,
+		"from", oldStatus,
+		"to", event.Status,
+		"user_id", userID)
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Event status updated successfully",
+		"event":   event,
+	})
+}
+
+func patchEventHandler(c *gin.Context) {
+	eventId, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		utils.Logger.Warn("Invalid event ID parameter", "id", c.Param("id"), "error", err)
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid event ID",
+		})
+		return
+	}
+
+	event, err := models.GetEventByID(eventId)
+	if err != nil {
+		utils.Logger.Error("Failed to retrieve event for patch", "event_id", eventId, "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to retrieve event",
+		})
+		return
+	}
+
+	if !canSeeEvent(c, event) {
+		return
+	}
+
+	userID := c.GetInt64("userID")
+	role := c.GetString("role")
+
+	if event.UserID != userID && !middlewares.HasPermission(c, models.PermEventsUpdateAny) {
+		utils.Logger.Warn("Unauthorized event patch attempt",
+			"event_id", eventId,
+			"event_owner", event.UserID,
+			"user_id", userID,
+			"role", role)
+		c.JSON(http.StatusForbidden, gin.H{
+			"error": "You are not authorized to update this event",
+		})
+		return
+	}
+
+	// A patch only touches the fields it names, so If-Match is optional
+	if c.GetHeader("If-Match") != "" && !checkIfMatch(c, event.Version) {
+		utils.Logger.Warn("Event patch precondition failed", "event_id", eventId, "if_match", c.GetHeader("If-Match"))
+		return
+	}
+
+	patch, ok := bindMergePatch(c)
+	if !ok {
+		utils.Logger.Warn("Invalid event patch payload", "event_id", eventId)
+		return
+	}
+
+	result, err := event.ApplyMergePatch(patch)
+	if err == nil {
+		err = validatePatched(event, result.Present)
+	}
+	if err != nil {
+		utils.Logger.Warn("Invalid event patch", "event_id", eventId, "error", err)
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	if len(result.Changed) > 0 {
+		err = event.UpdateFields(result.Changed)
+	}
+	if errors.Is(err, models.ErrVersionConflict) {
+		utils.Logger.Warn("Concurrent event patch rejected", "event_id", eventId, "user_id", userID)
+		versionConflict(c)
+		return
+	}
+	if errors.Is(err, models.ErrInvalidRecurrenceRule) {
+		utils.Logger.Warn("Invalid recurrence rule", "event_id", eventId, "error", err)
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+	if errors.Is(err, models.ErrInvalidRegistrationWindow) {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Registration must open before it closes",
+		})
+		return
+	}
+	if errors.Is(err, models.ErrSeatedApproval) {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Events with reserved seating cannot require approval",
+		})
+		return
+	}
+	if errors.Is(err, models.ErrUnknownVenue) {
+		utils.Logger.Warn("Event patched with unknown venue", "venue_id", event.VenueID)
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Venue does not exist",
+		})
+		return
+	}
+	if errors.Is(err, models.ErrVenueLocked) {
+		c.JSON(http.StatusConflict, gin.H{
+			"error": "The venue cannot change while seats are held or booked",
+			"code":  "venue_locked",
+		})
+		return
+	}
+	if errors.Is(err, models.ErrUnknownCategory) {
+		utils.Logger.Warn("Event patched with unknown category", "event_id", eventId, "category_id", event.CategoryID)
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Category does not exist",
+		})
+		return
+	}
+	if err != nil {
+		utils.Logger.Error("Failed to patch event",
+			"event_id", eventId,
+			"user_id", userID,
+			"error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to update event",
+		})
+		return
+	}
+
+	utils.Logger.Info("Event patched successfully",
+		"event_id", eventId,
+		"fields", result.Changed,
+		"user_id", userID)
+	c.Header("ETag", etag(event.Version))
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Event updated successfully",
+		"event":   event,
+	})
 }
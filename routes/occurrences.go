@@ -0,0 +1,238 @@
+package routes
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"example.com/event-booking-api/middlewares"
+	"example.com/event-booking-api/models"
+	"example.com/event-booking-api/utils"
+	"github.com/gin-gonic/gin"
+)
+
+func getEventOccurrencesHandler(c *gin.Context) {
+	eventId, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		utils.Logger.Warn("Invalid event ID parameter", "id", c.Param("id"), "error", err)
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid event ID",
+		})
+		return
+	}
+
+	var window struct {
+		From *time.Time `form:"from"`
+		To   *time.Time `form:"to"`
+	}
+	err = c.ShouldBindQuery(&window)
+	if err != nil {
+		utils.Logger.Warn("Invalid occurrence window", "event_id", eventId, "error", err)
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid query parameters",
+		})
+		return
+	}
+
+	event, err := models.GetEventByID(eventId)
+	if err != nil {
+		utils.Logger.Error("Failed to retrieve event for occurrences", "event_id", eventId, "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to retrieve event",
+		})
+		return
+	}
+
+	if !canSeeEvent(c, event) {
+		return
+	}
+
+	if !event.IsRecurring() {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Event is not recurring",
+		})
+		return
+	}
+
+	from := time.Now().UTC()
+	if window.From != nil {
+		from = *window.From
+	}
+	to := from.Add(models.DefaultOccurrenceWindow)
+	if window.To != nil {
+		to = *window.To
+	}
+
+	occurrences, err := event.ListOccurrences(from, to)
+	if err != nil {
+		utils.Logger.Error("Failed to expand event occurrences", "event_id", eventId, "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to retrieve occurrences",
+		})
+		return
+	}
+
+	utils.Logger.Debug("Retrieved event occurrences", "event_id", eventId, "count", len(occurrences))
+	c.JSON(http.StatusOK, occurrences)
+}
+
+func updateEventOccurrenceHandler(c *gin.Context) {
+	eventId, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		utils.Logger.Warn("Invalid event ID parameter", "id", c.Param("id"), "error", err)
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid event ID",
+		})
+		return
+	}
+
+	occurrenceDate, err := time.Parse(time.RFC3339, c.Param("date"))
+	if err != nil {
+		utils.Logger.Warn("Invalid occurrence date parameter", "date", c.Param("date"), "error", err)
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid occurrence date",
+		})
+		return
+	}
+
+	event, err := models.GetEventByID(eventId)
+	if err != nil {
+		utils.Logger.Error("Failed to retrieve event for occurrence update", "event_id", eventId, "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to retrieve event",
+		})
+		return
+	}
+
+	if !canSeeEvent(c, event) {
+		return
+	}
+
+	userID := c.GetInt64("userID")
+	role := c.GetString("role")
+
+	if event.UserID != userID && !middlewares.HasPermission(c, models.PermEventsUpdateAny) {
+		utils.Logger.Warn("Unauthorized occurrence update attempt",
+			"event_id", eventId,
+			"event_owner", event.UserID,
+			"user_id", userID,
+			"role", role)
+		c.JSON(http.StatusForbidden, gin.H{
+			"error": "You are not authorized to update this event",
+		})
+		return
+	}
+
+	override := models.OccurrenceOverride{}
+	err = c.ShouldBindJSON(&override)
+	if err != nil {
+		utils.Logger.Warn("Invalid occurrence update payload", "event_id", eventId, "error", err)
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid request payload",
+		})
+		return
+	}
+	override.OccurrenceDate = occurrenceDate
+
+	err = event.SaveOccurrenceOverride(&override)
+	if errors.Is(err, models.ErrInvalidOccurrence) {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error": "Date is not an occurrence of this event",
+		})
+		return
+	}
+	if err != nil {
+		utils.Logger.Error("Failed to update occurrence",
+			"event_id", eventId,
+			"occurrence_date", occurrenceDate,
+			"error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to update occurrence",
+		})
+		return
+	}
+
+	utils.Logger.Info("Occurrence updated successfully",
+		"event_id", eventId,
+		"occurrence_date", occurrenceDate,
+		"user_id", userID)
+	c.JSON(http.StatusOK, gin.H{
+		"message":  "Occurrence updated successfully",
+		"override": override,
+	})
+}
+
+func cancelEventOccurrenceHandler(c *gin.Context) {
+	eventId, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		utils.Logger.Warn("Invalid event ID parameter", "id", c.Param("id"), "error", err)
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid event ID",
+		})
+		return
+	}
+
+	occurrenceDate, err := time.Parse(time.RFC3339, c.Param("date"))
+	if err != nil {
+		utils.Logger.Warn("Invalid occurrence date parameter", "date", c.Param("date"), "error", err)
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid occurrence date",
+		})
+		return
+	}
+
+	event, err := models.GetEventByID(eventId)
+	if err != nil {
+		utils.Logger.Error("Failed to retrieve event for occurrence cancellation", "event_id", eventId, "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to retrieve event",
+		})
+		return
+	}
+
+	if !canSeeEvent(c, event) {
+		return
+	}
+
+	userID := c.GetInt64("userID")
+	role := c.GetString("role")
+
+	if event.UserID != userID && !middlewares.HasPermission(c, models.PermEventsUpdateAny) {
+		utils.Logger.Warn("Unauthorized occurrence cancellation attempt",
+			"event_id", eventId,
+			"event_owner", event.UserID,
+			"user_id", userID,
+			"role", role)
+		c.JSON(http.StatusForbidden, gin.H{
+			"error": "You are not authorized to update this event",
+		})
+		return
+	}
+
+	err = event.CancelOccurrence(occurrenceDate)
+	if errors.Is(err, models.ErrInvalidOccurrence) {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error": "Date is not an occurrence of this event",
+		})
+		return
+	}
+	if err != nil {
+		utils.Logger.Error("Failed to cancel occurrence",
+			"event_id", eventId,
+			"occurrence_date", occurrenceDate,
+			"error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to cancel occurrence",
+		})
+		return
+	}
+
+	utils.Logger.Info("Occurrence cancelled successfully",
+		"event_id", eventId,
+		"occurrence_date", occurrenceDate,
+		"user_id", userID)
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Occurrence cancelled successfully",
+	})
+}
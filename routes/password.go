@@ -0,0 +1,116 @@
+package routes
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+
+	"example.com/event-booking-api/mailer"
+	"example.com/event-booking-api/models"
+	"example.com/event-booking-api/utils"
+	"github.com/gin-gonic/gin"
+)
+
+// forgotPasswordHandler mails a password reset link to the account with the
+// given email. It answers the same whether or not there is such an account,
+// and as quickly, so it cannot be used to find out who has one.
+func forgotPasswordHandler(c *gin.Context) {
+	var payload struct {
+		Email string `json:"email" binding:"required"`
+	}
+	err := c.ShouldBindJSON(&payload)
+	if err != nil {
+		utils.Logger.Warn("Invalid forgot password payload", "error", err)
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid request payload",
+		})
+		return
+	}
+
+	// The lookup and the mail happen after responding, so the response time
+	// does not depend on whether the account exists
+	go sendPasswordReset(payload.Email)
+
+	c.JSON(http.StatusAccepted, gin.H{
+		"message": "If an account with this email exists, a password reset link has been sent to it",
+	})
+}
+
+// sendPasswordReset mails a reset link to the account with the email, if
+// there is one that signs in with a password.
+func sendPasswordReset(email string) {
+	user, err := models.GetUserByEmail(email)
+	if err != nil {
+		return
+	}
+
+	// Service accounts sign in with API keys only
+	if user.ServiceAccount {
+		return
+	}
+
+	token, err := user.CreatePasswordResetToken()
+	if err != nil {
+		utils.Logger.Error("Failed to create password reset token", "user_id", user.ID, "error", err)
+		return
+	}
+
+	err = mailer.Default.Send(passwordResetMessage(user.Email, token))
+	if err != nil {
+		utils.Logger.Error("Failed to send password reset email", "user_id", user.ID, "error", err)
+		return
+	}
+
+	utils.Logger.Info("Password reset email sent", "user_id", user.ID)
+}
+
+func passwordResetMessage(email, token string) mailer.Message {
+	link := utils.GetEnvString("PASSWORD_RESET_URL", "http://localhost:8080/reset-password") +
+		"?token=" + url.QueryEscape(token)
+	return mailer.Message{
+		To:      email,
+		Subject: "Reset your password",
+		Body: fmt.Sprintf("Someone asked to reset the password of your Event Booking account.\n\n"+
+			"To choose a new password, open this link within %d minutes:\n\n%s\n\n"+
+			"If it was not you, you can ignore this email.\n",
+			int(models.PasswordResetDuration.Minutes()), link),
+	}
+}
+
+func resetPasswordHandler(c *gin.Context) {
+	var payload struct {
+		Token    string `json:"token" binding:"required"`
+		Password string `json:"password" binding:"required"`
+	}
+	err := c.ShouldBindJSON(&payload)
+	if err != nil {
+		utils.Logger.Warn("Invalid password reset payload", "error", err)
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid request payload",
+		})
+		return
+	}
+
+	user, err := models.ResetPassword(payload.Token, payload.Password)
+	if errors.Is(err, models.ErrInvalidResetToken) {
+		utils.Logger.Warn("Invalid password reset token presented")
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Password reset link is invalid or has expired",
+			"code":  "invalid_reset_token",
+		})
+		return
+	}
+	if err != nil {
+		utils.Logger.Error("Failed to reset password", "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to reset password",
+		})
+		return
+	}
+
+	utils.Logger.Info("Password reset successfully", "user_id", user.ID)
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Password reset successfully, please log in again",
+	})
+}
@@ -0,0 +1,48 @@
+package routes
+
+import (
+	"encoding/json"
+	"mime"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
+)
+
+const mergePatchContentType = "application/merge-patch+json"
+
+// bindMergePatch reads an RFC 7396 merge patch from the request body. Both
+// application/merge-patch+json and plain application/json are accepted. It
+// reports whether the request may proceed; otherwise the response has
+// already been written.
+func bindMergePatch(c *gin.Context) (map[string]json.RawMessage, bool) {
+	mediaType, _, err := mime.ParseMediaType(c.GetHeader("Content-Type"))
+	if err != nil || (mediaType != mergePatchContentType && mediaType != binding.MIMEJSON) {
+		c.JSON(http.StatusUnsupportedMediaType, gin.H{
+			"error": "Content-Type must be " + mergePatchContentType,
+		})
+		return nil, false
+	}
+
+	// A patch that is not an object would replace the whole resource, which
+	// PATCH does not allow here
+	var patch map[string]json.RawMessage
+	err = json.NewDecoder(c.Request.Body).Decode(&patch)
+	if err != nil || patch == nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Request body must be a JSON object",
+		})
+		return nil, false
+	}
+	return patch, true
+}
+
+// validatePatched runs the binding rules of obj for the given fields only,
+// so a patch is not rejected for fields it did not touch.
+func validatePatched(obj any, fields []string) error {
+	if len(fields) == 0 {
+		return nil
+	}
+	return binding.Validator.Engine().(*validator.Validate).StructPartial(obj, fields...)
+}
@@ -0,0 +1,350 @@
+package routes
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"example.com/event-booking-api/middlewares"
+	"example.com/event-booking-api/models"
+	"example.com/event-booking-api/utils"
+	"github.com/gin-gonic/gin"
+)
+
+func getPromoCodesHandler(c *gin.Context) {
+	eventId, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		utils.Logger.Warn("Invalid event ID parameter", "id", c.Param("id"), "error", err)
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid event ID",
+		})
+		return
+	}
+
+	event, err := models.GetEventByID(eventId)
+	if err != nil {
+		utils.Logger.Error("Failed to retrieve event for promo codes", "event_id", eventId, "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to retrieve event",
+		})
+		return
+	}
+
+	if !canSeeEvent(c, event) {
+		return
+	}
+
+	userID := c.GetInt64("userID")
+	role := c.GetString("role")
+
+	// Codes are handed out by the organizer, so only they may list them
+	if event.UserID != userID && !middlewares.HasPermission(c, models.PermEventsUpdateAny) {
+		utils.Logger.Warn("Unauthorized promo code view attempt",
+			"event_id", eventId,
+			"event_owner", event.UserID,
+			"user_id", userID,
+			"role", role)
+		c.JSON(http.StatusForbidden, gin.H{
+			"error": "You are not authorized to manage promo codes for this event",
+		})
+		return
+	}
+
+	usage, err := models.GetPromoCodeUsage(eventId)
+	if err != nil {
+		utils.Logger.Error("Failed to retrieve promo codes", "event_id", eventId, "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to retrieve promo codes",
+		})
+		return
+	}
+
+	utils.Logger.Debug("Retrieved promo codes", "event_id", eventId, "count", len(usage))
+	c.JSON(http.StatusOK, usage)
+}
+
+func createPromoCodeHandler(c *gin.Context) {
+	eventId, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		utils.Logger.Warn("Invalid event ID parameter", "id", c.Param("id"), "error", err)
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid event ID",
+		})
+		return
+	}
+
+	event, err := models.GetEventByID(eventId)
+	if err != nil {
+		utils.Logger.Error("Failed to retrieve event for promo code creation", "event_id", eventId, "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to retrieve event",
+		})
+		return
+	}
+
+	if !canSeeEvent(c, event) {
+		return
+	}
+
+	userID := c.GetInt64("userID")
+	role := c.GetString("role")
+
+	if event.UserID != userID && !middlewares.HasPermission(c, models.PermEventsUpdateAny) {
+		utils.Logger.Warn("Unauthorized promo code creation attempt",
+			"event_id", eventId,
+			"event_owner", event.UserID,
+			"user_id", userID,
+			"role", role)
+		c.JSON(http.StatusForbidden, gin.H{
+			"error": "You are not authorized to manage promo codes for this event",
+		})
+		return
+	}
+
+	promoCode := models.PromoCode{}
+	err = c.ShouldBindJSON(&promoCode)
+	if err != nil {
+		utils.Logger.Warn("Invalid promo code payload", "event_id", eventId, "error", err)
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid request payload",
+		})
+		return
+	}
+	promoCode.EventID = eventId
+
+	err = promoCode.Save()
+	if errors.Is(err, models.ErrInvalidDiscount) {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "A percentage discount cannot exceed 100",
+		})
+		return
+	}
+	if errors.Is(err, models.ErrUnknownTicketType) {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Ticket type does not exist for this event",
+		})
+		return
+	}
+	if errors.Is(err, models.ErrDuplicatePromoCode) {
+		utils.Logger.Warn("Duplicate promo code creation attempt", "event_id", eventId, "code", promoCode.Code)
+		c.JSON(http.StatusConflict, gin.H{
+			"error": "Promo code with this code already exists",
+		})
+		return
+	}
+	if err != nil {
+		utils.Logger.Error("Failed to create promo code", "event_id", eventId, "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to create promo code",
+		})
+		return
+	}
+
+	utils.Logger.Info("Promo code created successfully",
+		"event_id", eventId,
+		"promo_code_id", promoCode.ID,
+		"code", promoCode.Code,
+		"user_id", userID)
+	c.JSON(http.StatusCreated, gin.H{
+		"message":    "Promo code created successfully",
+		"promo_code": promoCode,
+	})
+}
+
+func updatePromoCodeHandler(c *gin.Context) {
+	eventId, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		utils.Logger.Warn("Invalid event ID parameter", "id", c.Param("id"), "error", err)
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid event ID",
+		})
+		return
+	}
+
+	promoCodeId, err := strconv.ParseInt(c.Param("promoCodeId"), 10, 64)
+	if err != nil {
+		utils.Logger.Warn("Invalid promo code ID parameter", "id", c.Param("promoCodeId"), "error", err)
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid promo code ID",
+		})
+		return
+	}
+
+	event, err := models.GetEventByID(eventId)
+	if err != nil {
+		utils.Logger.Error("Failed to retrieve event for promo code update", "event_id", eventId, "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to retrieve event",
+		})
+		return
+	}
+
+	if !canSeeEvent(c, event) {
+		return
+	}
+
+	userID := c.GetInt64("userID")
+	role := c.GetString("role")
+
+	if event.UserID != userID && !middlewares.HasPermission(c, models.PermEventsUpdateAny) {
+		utils.Logger.Warn("Unauthorized promo code update attempt",
+			"event_id", eventId,
+			"event_owner", event.UserID,
+			"user_id", userID,
+			"role", role)
+		c.JSON(http.StatusForbidden, gin.H{
+			"error": "You are not authorized to manage promo codes for this event",
+		})
+		return
+	}
+
+	promoCode, err := models.GetPromoCode(eventId, promoCodeId)
+	if errors.Is(err, models.ErrUnknownPromoCode) {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error": "Promo code not found",
+		})
+		return
+	}
+	if err != nil {
+		utils.Logger.Error("Failed to retrieve promo code for update", "promo_code_id", promoCodeId, "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to retrieve promo code",
+		})
+		return
+	}
+
+	err = c.ShouldBindJSON(promoCode)
+	if err != nil {
+		utils.Logger.Warn("Invalid promo code payload", "promo_code_id", promoCodeId, "error", err)
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid request payload",
+		})
+		return
+	}
+	promoCode.ID = promoCodeId
+	promoCode.EventID = eventId
+
+	err = promoCode.Update()
+	if errors.Is(err, models.ErrInvalidDiscount) {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "A percentage discount cannot exceed 100",
+		})
+		return
+	}
+	if errors.Is(err, models.ErrUnknownTicketType) {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Ticket type does not exist for this event",
+		})
+		return
+	}
+	if errors.Is(err, models.ErrDuplicatePromoCode) {
+		utils.Logger.Warn("Duplicate promo code on update", "promo_code_id", promoCodeId, "code", promoCode.Code)
+		c.JSON(http.StatusConflict, gin.H{
+			"error": "Promo code with this code already exists",
+		})
+		return
+	}
+	if err != nil {
+		utils.Logger.Error("Failed to update promo code", "promo_code_id", promoCodeId, "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to update promo code",
+		})
+		return
+	}
+
+	utils.Logger.Info("Promo code updated successfully",
+		"event_id", eventId,
+		"promo_code_id", promoCodeId,
+		"user_id", userID)
+	c.JSON(http.StatusOK, gin.H{
+		"message":    "Promo code updated successfully",
+		"promo_code": promoCode,
+	})
+}
+
+func deletePromoCodeHandler(c *gin.Context) {
+	eventId, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		utils.Logger.Warn("Invalid event ID parameter", "id", c.Param("id"), "error", err)
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid event ID",
+		})
+		return
+	}
+
+	promoCodeId, err := strconv.ParseInt(c.Param("promoCodeId"), 10, 64)
+	if err != nil {
+		utils.Logger.Warn("Invalid promo code ID parameter", "id", c.Param("promoCodeId"), "error", err)
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid promo code ID",
+		})
+		return
+	}
+
+	event, err := models.GetEventByID(eventId)
+	if err != nil {
+		utils.Logger.Error("Failed to retrieve event for promo code deletion", "event_id", eventId, "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to retrieve event",
+		})
+		return
+	}
+
+	if !canSeeEvent(c, event) {
+		return
+	}
+
+	userID := c.GetInt64("userID")
+	role := c.GetString("role")
+
+	if event.UserID != userID && !middlewares.HasPermission(c, models.PermEventsUpdateAny) {
+		utils.Logger.Warn("Unauthorized promo code deletion attempt",
+			"event_id", eventId,
+			"event_owner", event.UserID,
+			"user_id", userID,
+			"role", role)
+		c.JSON(http.StatusForbidden, gin.H{
+			"error": "You are not authorized to manage promo codes for this event",
+		})
+		return
+	}
+
+	promoCode, err := models.GetPromoCode(eventId, promoCodeId)
+	if errors.Is(err, models.ErrUnknownPromoCode) {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error": "Promo code not found",
+		})
+		return
+	}
+	if err != nil {
+		utils.Logger.Error("Failed to retrieve promo code for deletion", "promo_code_id", promoCodeId, "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to retrieve promo code",
+		})
+		return
+	}
+
+	err = promoCode.Delete()
+	if errors.Is(err, models.ErrPromoCodeInUse) {
+		utils.Logger.Warn("Deletion of redeemed promo code", "promo_code_id", promoCodeId)
+		c.JSON(http.StatusConflict, gin.H{
+			"error": "Promo code has been redeemed and cannot be deleted",
+		})
+		return
+	}
+	if err != nil {
+		utils.Logger.Error("Failed to delete promo code", "promo_code_id", promoCodeId, "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to delete promo code",
+		})
+		return
+	}
+
+	utils.Logger.Info("Promo code deleted successfully",
+		"event_id", eventId,
+		"promo_code_id", promoCodeId,
+		"user_id", userID)
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Promo code deleted successfully",
+	})
+}
@@ -0,0 +1,131 @@
+package routes
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"example.com/event-booking-api/middlewares"
+	"example.com/event-booking-api/models"
+	"example.com/event-booking-api/utils"
+	"github.com/gin-gonic/gin"
+)
+
+type questionsRequest struct {
+	Questions []models.Question `json:"questions" binding:"max=50,dive"`
+}
+
+func getQuestionsHandler(c *gin.Context) {
+	eventId, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		utils.Logger.Warn("Invalid event ID parameter", "id", c.Param("id"), "error", err)
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid event ID",
+		})
+		return
+	}
+
+	event, err := models.GetEventByID(eventId)
+	if err != nil {
+		utils.Logger.Error("Failed to retrieve event for questions", "event_id", eventId, "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to retrieve event",
+		})
+		return
+	}
+
+	if !canSeeEvent(c, event) {
+		return
+	}
+
+	questions, err := models.GetQuestionsByEventID(eventId)
+	if err != nil {
+		utils.Logger.Error("Failed to retrieve questions", "event_id", eventId, "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to retrieve questions",
+		})
+		return
+	}
+
+	utils.Logger.Debug("Retrieved questions", "event_id", eventId, "count", len(questions))
+	c.JSON(http.StatusOK, questions)
+}
+
+func replaceQuestionsHandler(c *gin.Context) {
+	eventId, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		utils.Logger.Warn("Invalid event ID parameter", "id", c.Param("id"), "error", err)
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid event ID",
+		})
+		return
+	}
+
+	event, err := models.GetEventByID(eventId)
+	if err != nil {
+		utils.Logger.Error("Failed to retrieve event for question update", "event_id", eventId, "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to retrieve event",
+		})
+		return
+	}
+
+	if !canSeeEvent(c, event) {
+		return
+	}
+
+	userID := c.GetInt64("userID")
+	role := c.GetString("role")
+
+	if event.UserID != userID && !middlewares.HasPermission(c, models.PermEventsUpdateAny) {
+		utils.Logger.Warn("Unauthorized question update attempt",
+			"event_id", eventId,
+			"event_owner", event.UserID,
+			"user_id", userID,
+			"role", role)
+		c.JSON(http.StatusForbidden, gin.H{
+			"error": "You are not authorized to edit the questions of this event",
+		})
+		return
+	}
+
+	var request questionsRequest
+	err = c.ShouldBindJSON(&request)
+	if err != nil {
+		utils.Logger.Warn("Invalid questions payload", "event_id", eventId, "error", err)
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid request payload",
+		})
+		return
+	}
+
+	questions, err := models.ReplaceQuestions(eventId, request.Questions)
+	if errors.Is(err, models.ErrInvalidQuestion) {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+	if errors.Is(err, models.ErrUnknownQuestion) {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Question does not exist for this event",
+		})
+		return
+	}
+	if err != nil {
+		utils.Logger.Error("Failed to save questions", "event_id", eventId, "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to save questions",
+		})
+		return
+	}
+
+	utils.Logger.Info("Event questions updated successfully",
+		"event_id", eventId,
+		"count", len(questions),
+		"user_id", userID)
+	c.JSON(http.StatusOK, gin.H{
+		"message":   "Questions updated successfully",
+		"questions": questions,
+	})
+}
@@ -0,0 +1,167 @@
+package routes
+
+import (
+	"errors"
+	"net/http"
+
+	"example.com/event-booking-api/models"
+	"example.com/event-booking-api/utils"
+	"github.com/gin-gonic/gin"
+)
+
+func getPermissionsHandler(c *gin.Context) {
+	c.JSON(http.StatusOK, models.Permissions)
+}
+
+func getRolesHandler(c *gin.Context) {
+	roles, err := models.GetRoles()
+	if err != nil {
+		utils.Logger.Error("Failed to retrieve roles", "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to retrieve roles",
+		})
+		return
+	}
+	utils.Logger.Debug("Retrieved roles", "count", len(roles))
+	c.JSON(http.StatusOK, roles)
+}
+
+func createRoleHandler(c *gin.Context) {
+	role := models.Role{}
+	err := c.ShouldBindJSON(&role)
+	if err != nil {
+		utils.Logger.Warn("Invalid role creation payload", "error", err)
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid request payload",
+		})
+		return
+	}
+
+	err = role.Save()
+	if errors.Is(err, models.ErrUnknownPermission) {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Permission is not in the catalogue",
+		})
+		return
+	}
+	if errors.Is(err, models.ErrDuplicateRole) {
+		utils.Logger.Warn("Duplicate role creation attempt", "role", role.Name)
+		c.JSON(http.StatusConflict, gin.H{
+			"error": "Role with this name already exists",
+		})
+		return
+	}
+	if err != nil {
+		utils.Logger.Error("Failed to create role", "role", role.Name, "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to create role",
+		})
+		return
+	}
+
+	utils.Logger.Info("Role created successfully", "role", role.Name, "permissions", role.Permissions)
+	c.JSON(http.StatusCreated, gin.H{
+		"message": "Role created successfully",
+		"role":    role,
+	})
+}
+
+func updateRoleHandler(c *gin.Context) {
+	name := c.Param("name")
+	role, err := models.GetRole(name)
+	if errors.Is(err, models.ErrUnknownRole) {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error": "Role not found",
+		})
+		return
+	}
+	if err != nil {
+		utils.Logger.Error("Failed to retrieve role for update", "role", name, "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to retrieve role",
+		})
+		return
+	}
+
+	err = c.ShouldBindJSON(role)
+	if err != nil {
+		utils.Logger.Warn("Invalid role update payload", "role", name, "error", err)
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid request payload",
+		})
+		return
+	}
+	role.Name = name
+
+	err = role.Update()
+	if errors.Is(err, models.ErrUnknownPermission) {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Permission is not in the catalogue",
+		})
+		return
+	}
+	if errors.Is(err, models.ErrBuiltinRole) {
+		c.JSON(http.StatusConflict, gin.H{
+			"error": "Built-in roles cannot be changed",
+		})
+		return
+	}
+	if err != nil {
+		utils.Logger.Error("Failed to update role", "role", name, "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to update role",
+		})
+		return
+	}
+
+	utils.Logger.Info("Role updated successfully", "role", name, "permissions", role.Permissions)
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Role updated successfully",
+		"role":    role,
+	})
+}
+
+func deleteRoleHandler(c *gin.Context) {
+	name := c.Param("name")
+	role, err := models.GetRole(name)
+	if errors.Is(err, models.ErrUnknownRole) {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error": "Role not found",
+		})
+		return
+	}
+	if err != nil {
+		utils.Logger.Error("Failed to retrieve role for deletion", "role", name, "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to retrieve role",
+		})
+		return
+	}
+
+	err = role.Delete()
+	if errors.Is(err, models.ErrBuiltinRole) {
+		c.JSON(http.StatusConflict, gin.H{
+			"error": "Built-in roles cannot be deleted",
+		})
+		return
+	}
+	if errors.Is(err, models.ErrRoleInUse) {
+		utils.Logger.Warn("Deletion of role in use", "role", name)
+		c.JSON(http.StatusConflict, gin.H{
+			"error": "Role is assigned to users and cannot be deleted",
+		})
+		return
+	}
+	if err != nil {
+		utils.Logger.Error("Failed to delete role", "role", name, "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to delete role",
+		})
+		return
+	}
+
+	utils.Logger.Info("Role deleted successfully", "role", name)
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Role deleted successfully",
+	})
+}
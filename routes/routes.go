@@ -2,6 +2,7 @@ package routes
 
 import (
 	"example.com/event-booking-api/middlewares"
+	"example.com/event-booking-api/models"
 	"github.com/gin-gonic/gin"
 )
 
@@ -11,35 +12,130 @@ func RegisterRoutes(server *gin.Engine) {
 
 	server.POST("/users/signup", userSignupHandler)
 	server.POST("/users/login", userLoginHandler)
+	server.POST("/users/refresh", refreshTokenHandler)
+	server.POST("/users/password/forgot", forgotPasswordHandler)
+	server.POST("/users/password/reset", resetPasswordHandler)
+	server.POST("/users/email/verify", verifyEmailHandler)
 
-	// Temporary routes for testing without authentication
-	server.GET("/users", getUsersHandler)
+	// Public routes, which must stay reachable without authentication
 	server.GET("/events", getEventsHandler)
+	server.GET("/events/search", searchEventsHandler)
+	server.GET("/events/:id/ics", getEventICSHandler)
+	server.GET("/categories", getCategoriesHandler)
+	server.GET("/tags", getTagsHandler)
+
+	// Calendar feeds are authenticated by the secret token in the URL
+	server.GET("/calendar/:token", getCalendarFeedHandler)
 
 	// Protected routes
 	authenticated := server.Group("/")
 	authenticated.Use(middlewares.Authenticate)
 
+	// Requests made with an API key are limited to the scopes of the key
+	eventsRead := middlewares.RequireScope(models.ScopeEventsRead)
+	eventsWrite := middlewares.RequireScope(models.ScopeEventsWrite)
+	registrationsRead := middlewares.RequireScope(models.ScopeRegistrationsRead)
+	registrationsWrite := middlewares.RequireScope(models.ScopeRegistrationsWrite)
+	checkIn := middlewares.RequireScope(models.ScopeCheckIn)
+	usersRead := middlewares.RequireScope(models.ScopeUsersRead)
+	usersWrite := middlewares.RequireScope(models.ScopeUsersWrite)
+
 	// Event routes
 	// authenticated.GET("/events", getEventsHandler)
-	authenticated.POST("/events", createEventHandler)
-	authenticated.GET("/events/:id", getEventHandler)
-	authenticated.PUT("/events/:id", updateEventHandler)
-	authenticated.DELETE("/events/:id", deleteEventHandler)
+	authenticated.POST("/events", eventsWrite, middlewares.RequireVerifiedEmail, createEventHandler)
+	authenticated.GET("/events/:id", eventsRead, getEventHandler)
+	authenticated.PUT("/events/:id", eventsWrite, updateEventHandler)
+	authenticated.PATCH("/events/:id", eventsWrite, patchEventHandler)
+	authenticated.DELETE("/events/:id", eventsWrite, deleteEventHandler)
+	authenticated.PUT("/events/:id/status", eventsWrite, updateEventStatusHandler)
+
+	// Recurring event occurrences, identified by their RFC 3339 start date
+	authenticated.GET("/events/:id/occurrences", eventsRead, getEventOccurrencesHandler)
+	authenticated.PUT("/events/:id/occurrences/:date", eventsWrite, updateEventOccurrenceHandler)
+	authenticated.DELETE("/events/:id/occurrences/:date", eventsWrite, cancelEventOccurrenceHandler)
+
+	// Ticket types sold for an event
+	authenticated.GET("/events/:id/ticket-types", eventsRead, getTicketTypesHandler)
+	authenticated.POST("/events/:id/ticket-types", eventsWrite, createTicketTypeHandler)
+	authenticated.PUT("/events/:id/ticket-types/:ticketTypeId", eventsWrite, updateTicketTypeHandler)
+	authenticated.DELETE("/events/:id/ticket-types/:ticketTypeId", eventsWrite, deleteTicketTypeHandler)
+
+	// Promo codes of an event, managed by its organizer
+	authenticated.GET("/events/:id/promo-codes", eventsRead, getPromoCodesHandler)
+	authenticated.POST("/events/:id/promo-codes", eventsWrite, createPromoCodeHandler)
+	authenticated.PUT("/events/:id/promo-codes/:promoCodeId", eventsWrite, updatePromoCodeHandler)
+	authenticated.DELETE("/events/:id/promo-codes/:promoCodeId", eventsWrite, deletePromoCodeHandler)
+
+	// Reserved seating: hold seats, then register for them
+	authenticated.GET("/events/:id/seats", eventsRead, getEventSeatsHandler)
+	authenticated.POST("/events/:id/seat-holds", registrationsWrite, middlewares.RequireVerifiedEmail, holdSeatsHandler)
+	authenticated.DELETE("/events/:id/seat-holds", registrationsWrite, releaseSeatsHandler)
+
+	// Registration form questions
+	authenticated.GET("/events/:id/questions", eventsRead, getQuestionsHandler)
+	authenticated.PUT("/events/:id/questions", eventsWrite, replaceQuestionsHandler)
 
 	// Event registration routes
-	authenticated.POST("/events/:id/register", registerEventHandler)
-	authenticated.DELETE("/events/:id/register", unregisterEventHandler)
-	authenticated.GET("/events/:id/registrations", getEventRegistrationsHandler)
+	authenticated.POST("/events/:id/register", registrationsWrite, middlewares.RequireVerifiedEmail, registerEventHandler)
+	authenticated.DELETE("/events/:id/register", registrationsWrite, unregisterEventHandler)
+	authenticated.GET("/events/:id/registrations", registrationsRead, getEventRegistrationsHandler)
+	authenticated.GET("/events/:id/registrations/export", registrationsRead, exportEventRegistrationsHandler)
+	authenticated.POST("/events/:id/registrations/:registrationId/approve", registrationsWrite, approveRegistrationHandler)
+	authenticated.POST("/events/:id/registrations/:registrationId/reject", registrationsWrite, rejectRegistrationHandler)
+
+	// Tickets and door check-in
+	authenticated.GET("/registrations/:id/qr", registrationsRead, getRegistrationQRHandler)
+	authenticated.POST("/events/:id/check-in", checkIn, checkInHandler)
+
+	// Venues and their seat maps
+	authenticated.GET("/venues", eventsRead, getVenuesHandler)
+	authenticated.GET("/venues/:id", eventsRead, getVenueHandler)
+	authenticated.POST("/venues", eventsWrite, createVenueHandler)
+	authenticated.DELETE("/venues/:id", eventsWrite, deleteVenueHandler)
+
+	// Current user shortcuts
+	authenticated.GET("/me/events", eventsRead, getMyEventsHandler)
+	authenticated.GET("/me/registrations", registrationsRead, getMyRegistrationsHandler)
+	authenticated.GET("/me/applications", registrationsRead, getMyApplicationsHandler)
 
 	// User routes
-	authenticated.GET("/users/:id", getUserHandler)
-	authenticated.PUT("/users/:id", updateUserHandler)
-	authenticated.DELETE("/users/:id", deleteUserHandler)
+	authenticated.POST("/users/logout", middlewares.DenyAPIKeys, userLogoutHandler)
+	authenticated.POST("/users/email/resend", middlewares.DenyAPIKeys, resendVerificationHandler)
+	authenticated.GET("/users/:id", usersRead, getUserHandler)
+	authenticated.GET("/users/:id/registrations", registrationsRead, getUserRegistrationsHandler)
+	authenticated.PUT("/users/:id", usersWrite, updateUserHandler)
+	authenticated.PATCH("/users/:id", usersWrite, patchUserHandler)
+	authenticated.DELETE("/users/:id", usersWrite, deleteUserHandler)
+	authenticated.POST("/users/:id/calendar-token", usersWrite, rotateCalendarTokenHandler)
+
+	// API keys of the current user, which cannot be managed with an API key
+	authenticated.GET("/api-keys", middlewares.DenyAPIKeys, getAPIKeysHandler)
+	authenticated.POST("/api-keys", middlewares.DenyAPIKeys, createAPIKeyHandler)
+	authenticated.DELETE("/api-keys/:id", middlewares.DenyAPIKeys, deleteAPIKeyHandler)
 
-	// Admin-only routes
+	// Administration, each route guarded by its own permission
 	admin := authenticated.Group("/admin")
-	admin.Use(middlewares.Authenticate, middlewares.AuthorizeAdmin)
-	admin.GET("/users", getUsersHandler)
-	admin.PUT("/users/:id/role", updateUserRoleHandler)
+	admin.Use(middlewares.RequireScope(models.ScopeAdmin))
+	admin.GET("/users", middlewares.RequirePermission(models.PermUsersReadAny), getUsersHandler)
+	admin.PUT("/users/:id/role", middlewares.RequirePermission(models.PermUsersAssignRole), updateUserRoleHandler)
+	admin.GET("/users/deleted", middlewares.RequirePermission(models.PermUsersDeleteAny), getDeletedUsersHandler)
+	admin.POST("/users/:id/restore", middlewares.RequirePermission(models.PermUsersDeleteAny), restoreUserHandler)
+	admin.GET("/events/deleted", middlewares.RequirePermission(models.PermEventsDeleteAny), getDeletedEventsHandler)
+	admin.POST("/events/:id/restore", middlewares.RequirePermission(models.PermEventsDeleteAny), restoreEventHandler)
+	admin.GET("/categories", middlewares.RequirePermission(models.PermCategoriesManage), getCategoriesHandler)
+	admin.POST("/categories", middlewares.RequirePermission(models.PermCategoriesManage), createCategoryHandler)
+	admin.PUT("/categories/:id", middlewares.RequirePermission(models.PermCategoriesManage), updateCategoryHandler)
+	admin.DELETE("/categories/:id", middlewares.RequirePermission(models.PermCategoriesManage), deleteCategoryHandler)
+	admin.GET("/permissions", middlewares.RequirePermission(models.PermRolesManage), getPermissionsHandler)
+	admin.GET("/roles", middlewares.RequirePermission(models.PermRolesManage), getRolesHandler)
+	admin.POST("/roles", middlewares.RequirePermission(models.PermRolesManage), createRoleHandler)
+	admin.PUT("/roles/:name", middlewares.RequirePermission(models.PermRolesManage), updateRoleHandler)
+	admin.DELETE("/roles/:name", middlewares.RequirePermission(models.PermRolesManage), deleteRoleHandler)
+
+	manageServiceAccounts := middlewares.RequirePermission(models.PermServiceAccountsManage)
+	admin.GET("/service-accounts", manageServiceAccounts, middlewares.DenyAPIKeys, getServiceAccountsHandler)
+	admin.POST("/service-accounts", manageServiceAccounts, middlewares.DenyAPIKeys, createServiceAccountHandler)
+	admin.GET("/service-accounts/:id/api-keys", manageServiceAccounts, middlewares.DenyAPIKeys, getServiceAccountAPIKeysHandler)
+	admin.POST("/service-accounts/:id/api-keys", manageServiceAccounts, middlewares.DenyAPIKeys, createServiceAccountAPIKeyHandler)
+	admin.DELETE("/service-accounts/:id/api-keys/:keyId", manageServiceAccounts, middlewares.DenyAPIKeys, deleteServiceAccountAPIKeyHandler)
 }
@@ -0,0 +1,238 @@
+package routes
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"example.com/event-booking-api/models"
+	"example.com/event-booking-api/utils"
+	"github.com/gin-gonic/gin"
+)
+
+func getEventSeatsHandler(c *gin.Context) {
+	eventId, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		utils.Logger.Warn("Invalid event ID parameter", "id", c.Param("id"), "error", err)
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid event ID",
+		})
+		return
+	}
+
+	var query struct {
+		OccurrenceDate *time.Time `form:"occurrence_date"`
+	}
+	err = c.ShouldBindQuery(&query)
+	if err != nil {
+		utils.Logger.Warn("Invalid seat map query", "event_id", eventId, "error", err)
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid query parameters",
+		})
+		return
+	}
+
+	event, err := models.GetEventByID(eventId)
+	if err != nil {
+		utils.Logger.Error("Failed to retrieve event for seat map", "event_id", eventId, "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to retrieve event",
+		})
+		return
+	}
+
+	if !canSeeEvent(c, event) {
+		return
+	}
+
+	userID := c.GetInt64("userID")
+
+	seats, err := event.GetSeatAvailability(query.OccurrenceDate, userID)
+	if errors.Is(err, models.ErrNotSeated) {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error": "Event has no reserved seating",
+		})
+		return
+	}
+	if errors.Is(err, models.ErrOccurrenceRequired) {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "occurrence_date is required for seated series",
+		})
+		return
+	}
+	if errors.Is(err, models.ErrInvalidOccurrence) {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Date is not an occurrence of this event",
+		})
+		return
+	}
+	if err != nil {
+		utils.Logger.Error("Failed to retrieve seat map", "event_id", eventId, "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to retrieve seats",
+		})
+		return
+	}
+
+	utils.Logger.Debug("Retrieved seat map", "event_id", eventId, "seats", len(seats))
+	c.JSON(http.StatusOK, seats)
+}
+
+func holdSeatsHandler(c *gin.Context) {
+	eventId, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		utils.Logger.Warn("Invalid event ID parameter", "id", c.Param("id"), "error", err)
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid event ID",
+		})
+		return
+	}
+
+	var request struct {
+		SeatIDs        []int64    `json:"seat_ids" binding:"required,min=1,max=20,dive,gt=0"`
+		OccurrenceDate *time.Time `json:"occurrence_date"`
+	}
+	err = c.ShouldBindJSON(&request)
+	if err != nil {
+		utils.Logger.Warn("Invalid seat hold payload", "event_id", eventId, "error", err)
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid request payload",
+		})
+		return
+	}
+
+	event, err := models.GetEventByID(eventId)
+	if err != nil {
+		utils.Logger.Error("Failed to retrieve event for seat hold", "event_id", eventId, "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to retrieve event",
+		})
+		return
+	}
+
+	if !canSeeEvent(c, event) {
+		return
+	}
+
+	userID := c.GetInt64("userID")
+	hold, err := event.HoldSeats(userID, request.SeatIDs, request.OccurrenceDate)
+	if errors.Is(err, models.ErrNotSeated) {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Event has no reserved seating",
+		})
+		return
+	}
+	if errors.Is(err, models.ErrEventNotOpen) {
+		c.JSON(http.StatusConflict, gin.H{
+			"error": "Event is not open for registration",
+		})
+		return
+	}
+	if errors.Is(err, models.ErrRegistrationNotOpen) {
+		c.JSON(http.StatusConflict, gin.H{
+			"error":    "Registration for this event has not opened yet",
+			"opens_at": event.RegistrationOpensAt,
+		})
+		return
+	}
+	if errors.Is(err, models.ErrRegistrationClosed) {
+		c.JSON(http.StatusConflict, gin.H{
+			"error": "Registration for this event has closed",
+		})
+		return
+	}
+	if errors.Is(err, models.ErrOccurrenceRequired) {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "occurrence_date is required for seated series",
+		})
+		return
+	}
+	if errors.Is(err, models.ErrInvalidOccurrence) {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Date is not an occurrence of this event",
+		})
+		return
+	}
+	if errors.Is(err, models.ErrUnknownSeat) {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Seat does not exist in this event's venue",
+		})
+		return
+	}
+	if errors.Is(err, models.ErrSeatTaken) {
+		utils.Logger.Warn("Hold of taken seat", "event_id", eventId, "user_id", userID)
+		c.JSON(http.StatusConflict, gin.H{
+			"error": "One or more seats are already taken",
+		})
+		return
+	}
+	if err != nil {
+		utils.Logger.Error("Failed to hold seats", "event_id", eventId, "user_id", userID, "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to hold seats",
+		})
+		return
+	}
+
+	utils.Logger.Info("Seats held",
+		"event_id", eventId,
+		"user_id", userID,
+		"seats", len(hold.SeatIDs),
+		"expires_at", hold.ExpiresAt)
+	c.JSON(http.StatusCreated, gin.H{
+		"message": "Seats held successfully",
+		"hold":    hold,
+	})
+}
+
+func releaseSeatsHandler(c *gin.Context) {
+	eventId, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		utils.Logger.Warn("Invalid event ID parameter", "id", c.Param("id"), "error", err)
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid event ID",
+		})
+		return
+	}
+
+	var query struct {
+		OccurrenceDate *time.Time `form:"occurrence_date"`
+	}
+	err = c.ShouldBindQuery(&query)
+	if err != nil {
+		utils.Logger.Warn("Invalid seat release query", "event_id", eventId, "error", err)
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid query parameters",
+		})
+		return
+	}
+
+	event, err := models.GetEventByID(eventId)
+	if err != nil {
+		utils.Logger.Error("Failed to retrieve event for seat release", "event_id", eventId, "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to retrieve event",
+		})
+		return
+	}
+
+	if !canSeeEvent(c, event) {
+		return
+	}
+
+	userID := c.GetInt64("userID")
+	err = event.ReleaseSeats(userID, query.OccurrenceDate)
+	if err != nil {
+		utils.Logger.Error("Failed to release seats", "event_id", eventId, "user_id", userID, "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to release seats",
+		})
+		return
+	}
+
+	utils.Logger.Info("Seats released", "event_id", eventId, "user_id", userID)
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Seats released successfully",
+	})
+}
@@ -0,0 +1,322 @@
+package routes
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"example.com/event-booking-api/middlewares"
+	"example.com/event-booking-api/models"
+	"example.com/event-booking-api/utils"
+	"github.com/gin-gonic/gin"
+)
+
+func getTicketTypesHandler(c *gin.Context) {
+	eventId, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		utils.Logger.Warn("Invalid event ID parameter", "id", c.Param("id"), "error", err)
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid event ID",
+		})
+		return
+	}
+
+	event, err := models.GetEventByID(eventId)
+	if err != nil {
+		utils.Logger.Error("Failed to retrieve event for ticket types", "event_id", eventId, "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to retrieve event",
+		})
+		return
+	}
+
+	if !canSeeEvent(c, event) {
+		return
+	}
+
+	ticketTypes, err := models.GetTicketTypesByEventID(eventId)
+	if err != nil {
+		utils.Logger.Error("Failed to retrieve ticket types", "event_id", eventId, "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to retrieve ticket types",
+		})
+		return
+	}
+
+	utils.Logger.Debug("Retrieved ticket types", "event_id", eventId, "count", len(ticketTypes))
+	c.JSON(http.StatusOK, ticketTypes)
+}
+
+func createTicketTypeHandler(c *gin.Context) {
+	eventId, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		utils.Logger.Warn("Invalid event ID parameter", "id", c.Param("id"), "error", err)
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid event ID",
+		})
+		return
+	}
+
+	event, err := models.GetEventByID(eventId)
+	if err != nil {
+		utils.Logger.Error("Failed to retrieve event for ticket type creation", "event_id", eventId, "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to retrieve event",
+		})
+		return
+	}
+
+	if !canSeeEvent(c, event) {
+		return
+	}
+
+	userID := c.GetInt64("userID")
+	role := c.GetString("role")
+
+	if event.UserID != userID && !middlewares.HasPermission(c, models.PermEventsUpdateAny) {
+		utils.Logger.Warn("Unauthorized ticket type creation attempt",
+			"event_id", eventId,
+			"event_owner", event.UserID,
+			"user_id", userID,
+			"role", role)
+		c.JSON(http.StatusForbidden, gin.H{
+			"error": "You are not authorized to manage tickets for this event",
+		})
+		return
+	}
+
+	ticketType := models.TicketType{}
+	err = c.ShouldBindJSON(&ticketType)
+	if err != nil {
+		utils.Logger.Warn("Invalid ticket type payload", "event_id", eventId, "error", err)
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid request payload",
+		})
+		return
+	}
+	ticketType.EventID = eventId
+
+	err = ticketType.Save()
+	if errors.Is(err, models.ErrInvalidSalesWindow) {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Ticket sales must start before they end",
+		})
+		return
+	}
+	if errors.Is(err, models.ErrDuplicateTicketType) {
+		utils.Logger.Warn("Duplicate ticket type creation attempt", "event_id", eventId, "name", ticketType.Name)
+		c.JSON(http.StatusConflict, gin.H{
+			"error": "Ticket type with this name already exists",
+		})
+		return
+	}
+	if err != nil {
+		utils.Logger.Error("Failed to create ticket type", "event_id", eventId, "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to create ticket type",
+		})
+		return
+	}
+
+	utils.Logger.Info("Ticket type created successfully",
+		"event_id", eventId,
+		"ticket_type_id", ticketType.ID,
+		"name", ticketType.Name,
+		"user_id", userID)
+	c.JSON(http.StatusCreated, gin.H{
+		"message":     "Ticket type created successfully",
+		"ticket_type": ticketType,
+	})
+}
+
+func updateTicketTypeHandler(c *gin.Context) {
+	eventId, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		utils.Logger.Warn("Invalid event ID parameter", "id", c.Param("id"), "error", err)
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid event ID",
+		})
+		return
+	}
+
+	ticketTypeId, err := strconv.ParseInt(c.Param("ticketTypeId"), 10, 64)
+	if err != nil {
+		utils.Logger.Warn("Invalid ticket type ID parameter", "id", c.Param("ticketTypeId"), "error", err)
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid ticket type ID",
+		})
+		return
+	}
+
+	event, err := models.GetEventByID(eventId)
+	if err != nil {
+		utils.Logger.Error("Failed to retrieve event for ticket type update", "event_id", eventId, "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to retrieve event",
+		})
+		return
+	}
+
+	if !canSeeEvent(c, event) {
+		return
+	}
+
+	userID := c.GetInt64("userID")
+	role := c.GetString("role")
+
+	if event.UserID != userID && !middlewares.HasPermission(c, models.PermEventsUpdateAny) {
+		utils.Logger.Warn("Unauthorized ticket type update attempt",
+			"event_id", eventId,
+			"event_owner", event.UserID,
+			"user_id", userID,
+			"role", role)
+		c.JSON(http.StatusForbidden, gin.H{
+			"error": "You are not authorized to manage tickets for this event",
+		})
+		return
+	}
+
+	ticketType, err := models.GetTicketType(eventId, ticketTypeId)
+	if errors.Is(err, models.ErrUnknownTicketType) {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error": "Ticket type not found",
+		})
+		return
+	}
+	if err != nil {
+		utils.Logger.Error("Failed to retrieve ticket type for update", "ticket_type_id", ticketTypeId, "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to retrieve ticket type",
+		})
+		return
+	}
+
+	err = c.ShouldBindJSON(ticketType)
+	if err != nil {
+		utils.Logger.Warn("Invalid ticket type payload", "ticket_type_id", ticketTypeId, "error", err)
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid request payload",
+		})
+		return
+	}
+	ticketType.ID = ticketTypeId
+	ticketType.EventID = eventId
+
+	err = ticketType.Update()
+	if errors.Is(err, models.ErrInvalidSalesWindow) {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Ticket sales must start before they end",
+		})
+		return
+	}
+	if errors.Is(err, models.ErrDuplicateTicketType) {
+		utils.Logger.Warn("Duplicate ticket type name on update", "ticket_type_id", ticketTypeId, "name", ticketType.Name)
+		c.JSON(http.StatusConflict, gin.H{
+			"error": "Ticket type with this name already exists",
+		})
+		return
+	}
+	if err != nil {
+		utils.Logger.Error("Failed to update ticket type", "ticket_type_id", ticketTypeId, "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to update ticket type",
+		})
+		return
+	}
+
+	utils.Logger.Info("Ticket type updated successfully",
+		"event_id", eventId,
+		"ticket_type_id", ticketTypeId,
+		"user_id", userID)
+	c.JSON(http.StatusOK, gin.H{
+		"message":     "Ticket type updated successfully",
+		"ticket_type": ticketType,
+	})
+}
+
+func deleteTicketTypeHandler(c *gin.Context) {
+	eventId, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		utils.Logger.Warn("Invalid event ID parameter", "id", c.Param("id"), "error", err)
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid event ID",
+		})
+		return
+	}
+
+	ticketTypeId, err := strconv.ParseInt(c.Param("ticketTypeId"), 10, 64)
+	if err != nil {
+		utils.Logger.Warn("Invalid ticket type ID parameter", "id", c.Param("ticketTypeId"), "error", err)
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid ticket type ID",
+		})
+		return
+	}
+
+	event, err := models.GetEventByID(eventId)
+	if err != nil {
+		utils.Logger.Error("Failed to retrieve event for ticket type deletion", "event_id", eventId, "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to retrieve event",
+		})
+		return
+	}
+
+	if !canSeeEvent(c, event) {
+		return
+	}
+
+	userID := c.GetInt64("userID")
+	role := c.GetString("role")
+
+	if event.UserID != userID && !middlewares.HasPermission(c, models.PermEventsUpdateAny) {
+		utils.Logger.Warn("Unauthorized ticket type deletion attempt",
+			"event_id", eventId,
+			"event_owner", event.UserID,
+			"user_id", userID,
+			"role", role)
+		c.JSON(http.StatusForbidden, gin.H{
+			"error": "You are not authorized to manage tickets for this event",
+		})
+		return
+	}
+
+	ticketType, err := models.GetTicketType(eventId, ticketTypeId)
+	if errors.Is(err, models.ErrUnknownTicketType) {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error": "Ticket type not found",
+		})
+		return
+	}
+	if err != nil {
+		utils.Logger.Error("Failed to retrieve ticket type for deletion", "ticket_type_id", ticketTypeId, "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to retrieve ticket type",
+		})
+		return
+	}
+
+	err = ticketType.Delete()
+	if errors.Is(err, models.ErrTicketTypeInUse) {
+		utils.Logger.Warn("Deletion of ticket type with registrations", "ticket_type_id", ticketTypeId)
+		c.JSON(http.StatusConflict, gin.H{
+			"error": "Ticket type has registrations and cannot be deleted",
+		})
+		return
+	}
+	if err != nil {
+		utils.Logger.Error("Failed to delete ticket type", "ticket_type_id", ticketTypeId, "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to delete ticket type",
+		})
+		return
+	}
+
+	utils.Logger.Info("Ticket type deleted successfully",
+		"event_id", eventId,
+		"ticket_type_id", ticketTypeId,
+		"user_id", userID)
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Ticket type deleted successfully",
+	})
+}
@@ -1,9 +1,12 @@
 package routes
 
 import (
+	"errors"
+	"io"
 	"net/http"
 	"strconv"
 
+	"example.com/event-booking-api/middlewares"
 	"example.com/event-booking-api/models"
 	"example.com/event-booking-api/utils"
 	"github.com/gin-gonic/gin"
@@ -38,16 +41,27 @@ func userSignupHandler(c *gin.Context) {
 		return
 	}
 
+	err = sendVerificationEmail(&user)
+	if err != nil {
+		// The user can ask for another one
+		utils.Logger.Error("Failed to send verification email", "user_id", user.ID, "error", err)
+	}
+
 	utils.Logger.Info("User created successfully", "user_id", user.ID, "email", user.Email)
 	c.JSON(http.StatusCreated, gin.H{
 		"message": "User created successfully",
-		"user":    user,
+		"user":    user.ToPublic(),
 	})
 }
 
 func userLoginHandler(c *gin.Context) {
-	user := models.User{}
-	err := c.ShouldBindJSON(&user)
+	// Accounts from before email validation may not have a valid address, so
+	// the credentials are not bound into a User
+	var credentials struct {
+		Email    string `json:"email" binding:"required"`
+		Password string `json:"password" binding:"required"`
+	}
+	err := c.ShouldBindJSON(&credentials)
 	if err != nil {
 		utils.Logger.Warn("Invalid login payload", "error", err)
 		c.JSON(http.StatusBadRequest, gin.H{
@@ -55,6 +69,7 @@ func userLoginHandler(c *gin.Context) {
 		})
 		return
 	}
+	user := models.User{Email: credentials.Email, Password: credentials.Password}
 
 	err = user.Authenticate()
 	if err != nil {
@@ -65,7 +80,7 @@ func userLoginHandler(c *gin.Context) {
 		return
 	}
 
-	token, err := utils.GenerateToken(user.Email, user.ID, user.Role)
+	token, err := utils.GenerateToken(user.Email, user.ID, user.Role, user.TokenVersion)
 	if err != nil {
 		utils.Logger.Error("Failed to generate token", "user_id", user.ID, "email", user.Email, "error", err)
 		c.JSON(http.StatusInternalServerError, gin.H{
@@ -74,10 +89,138 @@ func userLoginHandler(c *gin.Context) {
 		return
 	}
 
+	refreshToken, err := user.IssueRefreshToken()
+	if err != nil {
+		utils.Logger.Error("Failed to issue refresh token", "user_id", user.ID, "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to generate token",
+		})
+		return
+	}
+
 	utils.Logger.Info("User logged in successfully", "user_id", user.ID, "email", user.Email)
 	c.JSON(http.StatusOK, gin.H{
-		"message": "Authentication successful",
-		"token":   token,
+		"message":       "Authentication successful",
+		"token":         token,
+		"refresh_token": refreshToken,
+		"expires_in":    int(utils.AccessTokenDuration.Seconds()),
+	})
+}
+
+func refreshTokenHandler(c *gin.Context) {
+	var payload struct {
+		RefreshToken string `json:"refresh_token" binding:"required"`
+	}
+	err := c.ShouldBindJSON(&payload)
+	if err != nil {
+		utils.Logger.Warn("Invalid token refresh payload", "error", err)
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid request payload",
+		})
+		return
+	}
+
+	user, refreshToken, err := models.RotateRefreshToken(payload.RefreshToken)
+	if errors.Is(err, models.ErrRefreshTokenReused) {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": "Refresh token was already used, please log in again",
+			"code":  "refresh_token_reused",
+		})
+		return
+	}
+	if errors.Is(err, models.ErrInvalidRefreshToken) {
+		utils.Logger.Warn("Invalid refresh token presented")
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": "Invalid refresh token",
+			"code":  "invalid_refresh_token",
+		})
+		return
+	}
+	if err != nil {
+		utils.Logger.Error("Failed to refresh token", "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to refresh token",
+		})
+		return
+	}
+
+	token, err := utils.GenerateToken(user.Email, user.ID, user.Role, user.TokenVersion)
+	if err != nil {
+		utils.Logger.Error("Failed to generate token", "user_id", user.ID, "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to generate token",
+		})
+		return
+	}
+
+	utils.Logger.Info("Token refreshed", "user_id", user.ID)
+	c.JSON(http.StatusOK, gin.H{
+		"message":       "Token refreshed successfully",
+		"token":         token,
+		"refresh_token": refreshToken,
+		"expires_in":    int(utils.AccessTokenDuration.Seconds()),
+	})
+}
+
+// userLogoutHandler revokes the access token it is called with and, if
+// given, the session of the refresh token. With "all" set it signs the user
+// out on every device instead.
+func userLogoutHandler(c *gin.Context) {
+	var payload struct {
+		RefreshToken string `json:"refresh_token"`
+		All          bool   `json:"all"`
+	}
+	err := c.ShouldBindJSON(&payload)
+	if err != nil && !errors.Is(err, io.EOF) {
+		utils.Logger.Warn("Invalid logout payload", "error", err)
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid request payload",
+		})
+		return
+	}
+
+	userID := c.GetInt64("userID")
+
+	if payload.All {
+		user := models.User{ID: userID}
+		err = user.RevokeAllSessions()
+		if err != nil {
+			utils.Logger.Error("Failed to log out everywhere", "user_id", userID, "error", err)
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error": "Failed to log out",
+			})
+			return
+		}
+		utils.Logger.Info("User logged out everywhere", "user_id", userID)
+		c.JSON(http.StatusOK, gin.H{
+			"message": "Logged out of all sessions",
+		})
+		return
+	}
+
+	err = models.RevokeAccessToken(c.GetString("tokenID"), c.GetTime("tokenExpiresAt"))
+	if err != nil {
+		utils.Logger.Error("Failed to revoke access token", "user_id", userID, "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to log out",
+		})
+		return
+	}
+
+	if payload.RefreshToken != "" {
+		err = models.RevokeRefreshToken(userID, payload.RefreshToken)
+		if err != nil {
+			utils.Logger.Error("Failed to revoke refresh token", "user_id", userID, "error", err)
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error": "Failed to log out",
+			})
+			return
+		}
+	}
+
+	utils.Logger.Info("User logged out", "user_id", userID)
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Logged out successfully",
 	})
 }
 
@@ -93,7 +236,7 @@ func updateUserHandler(c *gin.Context) {
 
 	tokenUserID := c.GetInt64("userID")
 	role := c.GetString("role")
-	if tokenUserID != userID && role != "admin" {
+	if tokenUserID != userID && !middlewares.HasPermission(c, models.PermUsersUpdateAny) {
 		utils.Logger.Warn("Unauthorized user update attempt",
 			"target_user_id", userID,
 			"token_user_id", tokenUserID,
@@ -113,7 +256,15 @@ func updateUserHandler(c *gin.Context) {
 		return
 	}
 
-	err = c.ShouldBindJSON(&user)
+	if !checkIfMatch(c, user.Version) {
+		utils.Logger.Warn("User update precondition failed", "user_id", userID, "if_match", c.GetHeader("If-Match"))
+		return
+	}
+
+	// Bind into a fresh user so the body cannot touch the role or keep the
+	// stored password hash
+	updatedUser := models.User{}
+	err = c.ShouldBindJSON(&updatedUser)
 	if err != nil {
 		utils.Logger.Warn("Invalid user update payload", "user_id", userID, "error", err)
 		c.JSON(http.StatusBadRequest, gin.H{
@@ -121,8 +272,23 @@ func updateUserHandler(c *gin.Context) {
 		})
 		return
 	}
+	oldEmail := user.Email
+	user.Email = updatedUser.Email
+	user.Password = updatedUser.Password
 
 	err = user.Update()
+	if errors.Is(err, models.ErrVersionConflict) {
+		utils.Logger.Warn("Concurrent user update rejected", "user_id", userID)
+		versionConflict(c)
+		return
+	}
+	if errors.Is(err, models.ErrEmailTaken) {
+		utils.Logger.Warn("User update with email in use", "user_id", userID, "email", user.Email)
+		c.JSON(http.StatusConflict, gin.H{
+			"error": "User with this email already exists",
+		})
+		return
+	}
 	if err != nil {
 		utils.Logger.Error("Failed to update user", "user_id", userID, "error", err)
 		c.JSON(http.StatusInternalServerError, gin.H{
@@ -131,10 +297,18 @@ func updateUserHandler(c *gin.Context) {
 		return
 	}
 
+	if user.Email != oldEmail {
+		err = sendVerificationEmail(user)
+		if err != nil {
+			utils.Logger.Error("Failed to send verification email", "user_id", userID, "error", err)
+		}
+	}
+
 	utils.Logger.Info("User updated successfully", "user_id", userID, "email", user.Email)
+	c.Header("ETag", etag(user.Version))
 	c.JSON(http.StatusOK, gin.H{
 		"message": "User updated successfully",
-		"user":    user,
+		"user":    user.ToPublic(),
 	})
 }
 
@@ -169,7 +343,7 @@ func getUserHandler(c *gin.Context) {
 
 	tokenUserID := c.GetInt64("userID")
 	role := c.GetString("role")
-	if tokenUserID != userID && role != "admin" {
+	if tokenUserID != userID && !middlewares.HasPermission(c, models.PermUsersReadAny) {
 		utils.Logger.Warn("Unauthorized user view attempt",
 			"target_user_id", userID,
 			"token_user_id", tokenUserID,
@@ -192,6 +366,7 @@ func getUserHandler(c *gin.Context) {
 	sanitizedUser := user.ToPublic()
 
 	utils.Logger.Debug("Retrieved user", "user_id", userID, "email", user.Email)
+	c.Header("ETag", etag(user.Version))
 	c.JSON(http.StatusOK, sanitizedUser)
 }
 
@@ -207,7 +382,7 @@ func deleteUserHandler(c *gin.Context) {
 
 	role := c.GetString("role")
 	tokenUserID := c.GetInt64("userID")
-	if userID != tokenUserID && role != "admin" {
+	if userID != tokenUserID && !middlewares.HasPermission(c, models.PermUsersDeleteAny) {
 		utils.Logger.Warn("Unauthorized user deletion attempt",
 			"target_user_id", userID,
 			"token_user_id", tokenUserID,
@@ -262,7 +437,7 @@ func updateUserRoleHandler(c *gin.Context) {
 	}
 
 	var payload struct {
-		Role string `json:"role"`
+		Role string `json:"role" binding:"required"`
 	}
 
 	err = c.ShouldBindJSON(&payload)
@@ -277,7 +452,19 @@ func updateUserRoleHandler(c *gin.Context) {
 	oldRole := user.Role
 	user.Role = payload.Role
 
-	err = user.Update()
+	err = user.UpdateFields([]string{"role"})
+	if errors.Is(err, models.ErrVersionConflict) {
+		utils.Logger.Warn("Concurrent user role update rejected", "user_id", userID)
+		versionConflict(c)
+		return
+	}
+	if errors.Is(err, models.ErrUnknownRole) {
+		utils.Logger.Warn("Role update with unknown role", "user_id", userID, "role", payload.Role)
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Role does not exist",
+		})
+		return
+	}
 	if err != nil {
 		utils.Logger.Error("Failed to update user role",
 			"user_id", userID,
@@ -297,6 +484,155 @@ func updateUserRoleHandler(c *gin.Context) {
 		"new_role", user.Role)
 	c.JSON(http.StatusOK, gin.H{
 		"message": "User role updated successfully",
-		"user":    user,
+		"user":    user.ToPublic(),
 	})
 }
+
+func patchUserHandler(c *gin.Context) {
+	userID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		utils.Logger.Warn("Invalid user ID parameter", "id", c.Param("id"), "error", err)
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid user ID",
+		})
+		return
+	}
+
+	tokenUserID := c.GetInt64("userID")
+	role := c.GetString("role")
+	if tokenUserID != userID && !middlewares.HasPermission(c, models.PermUsersUpdateAny) {
+		utils.Logger.Warn("Unauthorized user patch attempt",
+			"target_user_id", userID,
+			"token_user_id", tokenUserID,
+			"role", role)
+		c.JSON(http.StatusForbidden, gin.H{
+			"error": "You are not authorized to update this user",
+		})
+		return
+	}
+
+	user, err := models.GetUserByID(userID)
+	if err != nil {
+		utils.Logger.Error("Failed to retrieve user for patch", "user_id", userID, "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to retrieve user",
+		})
+		return
+	}
+
+	// A patch only touches the fields it names, so If-Match is optional
+	if c.GetHeader("If-Match") != "" && !checkIfMatch(c, user.Version) {
+		utils.Logger.Warn("User patch precondition failed", "user_id", userID, "if_match", c.GetHeader("If-Match"))
+		return
+	}
+
+	patch, ok := bindMergePatch(c)
+	if !ok {
+		utils.Logger.Warn("Invalid user patch payload", "user_id", userID)
+		return
+	}
+
+	oldEmail := user.Email
+	result, err := user.ApplyMergePatch(patch)
+	if err == nil {
+		err = validatePatched(user, result.Present)
+	}
+	if err != nil {
+		utils.Logger.Warn("Invalid user patch", "user_id", userID, "error", err)
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	if len(result.Changed) > 0 {
+		err = user.UpdateFields(result.Changed)
+	}
+	if errors.Is(err, models.ErrVersionConflict) {
+		utils.Logger.Warn("Concurrent user patch rejected", "user_id", userID)
+		versionConflict(c)
+		return
+	}
+	if errors.Is(err, models.ErrEmailTaken) {
+		utils.Logger.Warn("User patch with email in use", "user_id", userID, "email", user.Email)
+		c.JSON(http.StatusConflict, gin.H{
+			"error": "User with this email already exists",
+		})
+		return
+	}
+	if err != nil {
+		utils.Logger.Error("Failed to patch user", "user_id", userID, "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to update user",
+		})
+		return
+	}
+
+	if user.Email != oldEmail {
+		err = sendVerificationEmail(user)
+		if err != nil {
+			utils.Logger.Error("Failed to send verification email", "user_id", userID, "error", err)
+		}
+	}
+
+	utils.Logger.Info("User patched successfully", "user_id", userID, "fields", result.Changed)
+	c.Header("ETag", etag(user.Version))
+	c.JSON(http.StatusOK, gin.H{
+		"message": "User updated successfully",
+		"user":    user.ToPublic(),
+	})
+}
+
+func getUserRegistrationsHandler(c *gin.Context) {
+	userID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		utils.Logger.Warn("Invalid user ID parameter", "id", c.Param("id"), "error", err)
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid user ID",
+		})
+		return
+	}
+
+	tokenUserID := c.GetInt64("userID")
+	role := c.GetString("role")
+	if tokenUserID != userID && !middlewares.HasPermission(c, models.PermRegistrationsReadAny) {
+		utils.Logger.Warn("Unauthorized user registrations view attempt",
+			"target_user_id", userID,
+			"token_user_id", tokenUserID,
+			"role", role)
+		c.JSON(http.StatusForbidden, gin.H{
+			"error": "You are not authorized to view this user's registrations",
+		})
+		return
+	}
+
+	listUserRegistrations(c, userID)
+}
+
+func getMyRegistrationsHandler(c *gin.Context) {
+	listUserRegistrations(c, c.GetInt64("userID"))
+}
+
+func listUserRegistrations(c *gin.Context, userID int64) {
+	filter := models.UserRegistrationFilter{}
+	err := c.ShouldBindQuery(&filter)
+	if err != nil {
+		utils.Logger.Warn("Invalid registration listing parameters", "user_id", userID, "error", err)
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid query parameters",
+		})
+		return
+	}
+
+	page, err := models.GetRegistrationsForUser(userID, filter)
+	if err != nil {
+		utils.Logger.Error("Failed to retrieve user registrations", "user_id", userID, "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to retrieve registrations",
+		})
+		return
+	}
+
+	utils.Logger.Debug("Retrieved user registrations", "user_id", userID, "count", len(page.Registrations), "total", page.Total)
+	c.JSON(http.StatusOK, page)
+}
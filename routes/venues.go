@@ -0,0 +1,151 @@
+package routes
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"example.com/event-booking-api/middlewares"
+	"example.com/event-booking-api/models"
+	"example.com/event-booking-api/utils"
+	"github.com/gin-gonic/gin"
+)
+
+func getVenuesHandler(c *gin.Context) {
+	venues, err := models.GetVenues()
+	if err != nil {
+		utils.Logger.Error("Failed to retrieve venues", "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to retrieve venues",
+		})
+		return
+	}
+	utils.Logger.Debug("Retrieved venues", "count", len(venues))
+	c.JSON(http.StatusOK, venues)
+}
+
+func getVenueHandler(c *gin.Context) {
+	venueId, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		utils.Logger.Warn("Invalid venue ID parameter", "id", c.Param("id"), "error", err)
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid venue ID",
+		})
+		return
+	}
+
+	venue, err := models.GetVenueByID(venueId)
+	if errors.Is(err, models.ErrUnknownVenue) {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error": "Venue not found",
+		})
+		return
+	}
+	if err != nil {
+		utils.Logger.Error("Failed to retrieve venue", "venue_id", venueId, "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to retrieve venue",
+		})
+		return
+	}
+
+	utils.Logger.Debug("Retrieved venue", "venue_id", venueId)
+	c.JSON(http.StatusOK, venue)
+}
+
+func createVenueHandler(c *gin.Context) {
+	venue := models.Venue{}
+	err := c.ShouldBindJSON(&venue)
+	if err != nil {
+		utils.Logger.Warn("Invalid venue creation payload", "error", err)
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid request payload",
+		})
+		return
+	}
+
+	userID := c.GetInt64("userID")
+	venue.UserID = &userID
+
+	err = venue.Save()
+	if errors.Is(err, models.ErrInvalidVenue) {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Section names, row labels and seat labels must be unique within their parent",
+		})
+		return
+	}
+	if err != nil {
+		utils.Logger.Error("Failed to create venue", "name", venue.Name, "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to create venue",
+		})
+		return
+	}
+
+	utils.Logger.Info("Venue created successfully", "venue_id", venue.ID, "name", venue.Name, "user_id", userID)
+	c.JSON(http.StatusCreated, gin.H{
+		"message": "Venue created successfully",
+		"venue":   venue,
+	})
+}
+
+func deleteVenueHandler(c *gin.Context) {
+	venueId, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		utils.Logger.Warn("Invalid venue ID parameter", "id", c.Param("id"), "error", err)
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid venue ID",
+		})
+		return
+	}
+
+	venue, err := models.GetVenueByID(venueId)
+	if errors.Is(err, models.ErrUnknownVenue) {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error": "Venue not found",
+		})
+		return
+	}
+	if err != nil {
+		utils.Logger.Error("Failed to retrieve venue for deletion", "venue_id", venueId, "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to retrieve venue",
+		})
+		return
+	}
+
+	userID := c.GetInt64("userID")
+	role := c.GetString("role")
+
+	if (venue.UserID == nil || *venue.UserID != userID) && !middlewares.HasPermission(c, models.PermVenuesDeleteAny) {
+		utils.Logger.Warn("Unauthorized venue deletion attempt",
+			"venue_id", venueId,
+			"user_id", userID,
+			"role", role)
+		c.JSON(http.StatusForbidden, gin.H{
+			"error": "You are not authorized to delete this venue",
+		})
+		return
+	}
+
+	err = venue.Delete()
+	if errors.Is(err, models.ErrVenueInUse) {
+		utils.Logger.Warn("Deletion of venue in use", "venue_id", venueId)
+		c.JSON(http.StatusConflict, gin.H{
+			"error": "Venue is used by an event and cannot be deleted",
+		})
+		return
+	}
+	if err != nil {
+		utils.Logger.Error("Failed to delete venue", "venue_id", venueId, "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to delete venue",
+		})
+		return
+	}
+
+	utils.Logger.Info("Venue deleted successfully", "venue_id", venueId, "user_id", userID)
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Venue deleted successfully",
+	})
+}
@@ -0,0 +1,23 @@
+package routes
+
+import (
+	"net/http"
+
+	"example.com/event-booking-api/middlewares"
+	"example.com/event-booking-api/models"
+	"github.com/gin-gonic/gin"
+)
+
+// canSeeEvent hides drafts from everyone but their owner and users allowed
+// to read any event. Others get 404, so they cannot tell a draft exists. It
+// reports whether the request may proceed; otherwise the response has
+// already been written.
+func canSeeEvent(c *gin.Context, event *models.Event) bool {
+	if event.IsDraft() && event.UserID != c.GetInt64("userID") && !middlewares.HasPermission(c, models.PermEventsReadAny) {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error": "Event not found",
+		})
+		return false
+	}
+	return true
+}
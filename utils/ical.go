@@ -0,0 +1,113 @@
+package utils
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+const (
+	icalProductID  = "-//Event Booking API//EN"
+	icalTimeLayout = "20060102T150405Z"
+	// RFC 5545 limits content lines to 75 octets, excluding the line break
+	icalMaxLineLength = 75
+)
+
+// CalendarEvent is the data needed to render one VEVENT.
+type CalendarEvent struct {
+	UID          string
+	Summary      string
+	Description  string
+	Location     string
+	Start        time.Time
+	Status       string // CONFIRMED, TENTATIVE or CANCELLED; omitted when empty
+	RRule        string
+	ExDates      []time.Time
+	RecurrenceID *time.Time // set on overrides of a single occurrence
+}
+
+// WriteCalendar renders events as an RFC 5545 VCALENDAR.
+func WriteCalendar(w io.Writer, name string, events []CalendarEvent) error {
+	bw := bufio.NewWriter(w)
+	now := time.Now()
+
+	writeICalLine(bw, "BEGIN:VCALENDAR")
+	writeICalLine(bw, "VERSION:2.0")
+	writeICalLine(bw, "PRODID:"+icalProductID)
+	writeICalLine(bw, "CALSCALE:GREGORIAN")
+	writeICalLine(bw, "METHOD:PUBLISH")
+	if name != "" {
+		writeICalLine(bw, "X-WR-CALNAME:"+escapeICalText(name))
+	}
+
+	for _, e := range events {
+		writeICalLine(bw, "BEGIN:VEVENT")
+		writeICalLine(bw, "UID:"+e.UID)
+		writeICalLine(bw, "DTSTAMP:"+formatICalTime(now))
+		writeICalLine(bw, "DTSTART:"+formatICalTime(e.Start))
+		if e.RecurrenceID != nil {
+			writeICalLine(bw, "RECURRENCE-ID:"+formatICalTime(*e.RecurrenceID))
+		}
+		if e.RRule != "" {
+			writeICalLine(bw, "RRULE:"+strings.TrimPrefix(e.RRule, "RRULE:"))
+		}
+		if len(e.ExDates) > 0 {
+			dates := make([]string, len(e.ExDates))
+			for i, d := range e.ExDates {
+				dates[i] = formatICalTime(d)
+			}
+			writeICalLine(bw, "EXDATE:"+strings.Join(dates, ","))
+		}
+		writeICalLine(bw, "SUMMARY:"+escapeICalText(e.Summary))
+		if e.Description != "" {
+			writeICalLine(bw, "DESCRIPTION:"+escapeICalText(e.Description))
+		}
+		if e.Location != "" {
+			writeICalLine(bw, "LOCATION:"+escapeICalText(e.Location))
+		}
+		if e.Status != "" {
+			writeICalLine(bw, "STATUS:"+e.Status)
+		}
+		writeICalLine(bw, "END:VEVENT")
+	}
+
+	writeICalLine(bw, "END:VCALENDAR")
+	return bw.Flush()
+}
+
+func formatICalTime(t time.Time) string {
+	return t.UTC().Format(icalTimeLayout)
+}
+
+func escapeICalText(s string) string {
+	s = strings.ReplaceAll(s, "\r\n", "\n")
+	return strings.NewReplacer(
+		`\`, `\\`,
+		";", `\;`,
+		",", `\,`,
+		"\n", `\n`,
+	).Replace(s)
+}
+
+// writeICalLine writes a content line, folding it so that no physical line
+// exceeds 75 octets. Folds never split a multi-byte UTF-8 sequence.
+func writeICalLine(w *bufio.Writer, line string) {
+	limit := icalMaxLineLength
+	for len(line) > limit {
+		cut := limit
+		for cut > 0 && !isUTF8Start(line[cut]) {
+			cut--
+		}
+		fmt.Fprintf(w, "%s\r\n ", line[:cut])
+		line = line[cut:]
+		// continuation lines start with a space, which counts toward the limit
+		limit = icalMaxLineLength - 1
+	}
+	fmt.Fprintf(w, "%s\r\n", line)
+}
+
+func isUTF8Start(b byte) bool {
+	return b&0xC0 != 0x80
+}
@@ -7,19 +7,47 @@ import (
 	"github.com/golang-jwt/jwt/v5"
 )
 
-func GenerateToken(email string, userID int64, role string) (string, error) {
+// AccessTokenDuration is how long an access token is valid. Clients get a
+// new one with their refresh token.
+var AccessTokenDuration = 15 * time.Minute
+
+// AccessClaims is what a verified access token says about its bearer.
+type AccessClaims struct {
+	UserID int64
+	Role   string
+
+	// TokenID is the token's jti, by which it can be revoked on its own.
+	TokenID string
+
+	// TokenVersion is the user's token version when the token was issued.
+	// Bumping the version revokes every token issued before.
+	TokenVersion int
+
+	ExpiresAt time.Time
+}
+
+func GenerateToken(email string, userID int64, role string, tokenVersion int) (string, error) {
+	tokenID, err := GenerateSecureToken(16)
+	if err != nil {
+		return "", err
+	}
+
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
 		"email":   email,
 		"user_id": userID,
 		"role":    role,
-		"exp":     time.Now().Add(time.Hour * 2).Unix(),
+		"jti":     tokenID,
+		"ver":     tokenVersion,
+		"exp":     time.Now().Add(AccessTokenDuration).Unix(),
 	})
 
 	secretKey := getSecretKey()
 	return token.SignedString([]byte(secretKey))
 }
 
-func VerifyToken(tokenString string) (userID int64, role string, err error) {
+// VerifyToken checks the signature and expiry of an access token. Whether it
+// has been revoked is up to the caller.
+func VerifyToken(tokenString string) (*AccessClaims, error) {
 	token, err := jwt.Parse(tokenString, func(token *jwt.Token) (any, error) {
 		_, ok := token.Method.(*jwt.SigningMethodHMAC)
 		if !ok {
@@ -31,30 +59,56 @@ func VerifyToken(tokenString string) (userID int64, role string, err error) {
 	})
 
 	if err != nil {
-		return 0, "", err
+		return nil, err
 	}
 
 	if !token.Valid {
-		return 0, "", errors.New("invalid token")
+		return nil, errors.New("invalid token")
 	}
 
 	claims, ok := token.Claims.(jwt.MapClaims)
 	if !ok {
-		return 0, "", errors.New("invalid token claims")
+		return nil, errors.New("invalid token claims")
+	}
+
+	// Other signed codes, such as tickets, carry a type and are not access
+	// tokens
+	if _, typed := claims["type"]; typed {
+		return nil, errors.New("not an access token")
 	}
 
 	floatUserID, ok := claims["user_id"].(float64)
 	if !ok {
-		return 0, "", errors.New("invalid user_id in token")
+		return nil, errors.New("invalid user_id in token")
 	}
-	userID = int64(floatUserID)
 
-	role, ok = claims["role"].(string)
+	role, ok := claims["role"].(string)
 	if !ok {
-		return 0, "", errors.New("invalid role in token")
+		return nil, errors.New("invalid role in token")
+	}
+
+	tokenID, ok := claims["jti"].(string)
+	if !ok || tokenID == "" {
+		return nil, errors.New("invalid jti in token")
+	}
+
+	floatVersion, ok := claims["ver"].(float64)
+	if !ok {
+		return nil, errors.New("invalid ver in token")
+	}
+
+	expiresAt, err := claims.GetExpirationTime()
+	if err != nil || expiresAt == nil {
+		return nil, errors.New("invalid exp in token")
 	}
 
-	return userID, role, nil
+	return &AccessClaims{
+		UserID:       int64(floatUserID),
+		Role:         role,
+		TokenID:      tokenID,
+		TokenVersion: int(floatVersion),
+		ExpiresAt:    expiresAt.Time,
+	}, nil
 }
 
 func getSecretKey() string {
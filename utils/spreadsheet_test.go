@@ -0,0 +1,242 @@
+package utils
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/csv"
+	"encoding/xml"
+	"io"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestXLSXColumn(t *testing.T) {
+	tests := []struct {
+		index int
+		want  string
+	}{
+		{0, "A"},
+		{1, "B"},
+		{25, "Z"},
+		{26, "AA"},
+		{27, "AB"},
+		{51, "AZ"},
+		{52, "BA"},
+		{701, "ZZ"},
+		{702, "AAA"},
+		{16383, "XFD"},
+	}
+	for _, tt := range tests {
+		got := xlsxColumn(tt.index)
+		if got != tt.want {
+			t.Errorf("xlsxColumn(%d) = %q, want %q", tt.index, got, tt.want)
+		}
+	}
+}
+
+func TestCSVWriterEscapesFormulas(t *testing.T) {
+	tests := []struct {
+		cell any
+		want string
+	}{
+		{"=SUM(A1:A2)", "'=SUM(A1:A2)"},
+		{"+1", "'+1"},
+		{"-1", "'-1"},
+		{"@cmd", "'@cmd"},
+		{"\tindented", "'\tindented"},
+		{"\rreturn", "'\rreturn"},
+		{"plain", "plain"},
+		{"a=b", "a=b"},
+		{"", ""},
+		{nil, ""},
+		{-3, "-3"},
+		{2.5, "2.5"},
+		{time.Date(2024, 5, 1, 12, 30, 0, 0, time.FixedZone("CEST", 2*60*60)), "2024-05-01T10:30:00Z"},
+	}
+	for _, tt := range tests {
+		var buf bytes.Buffer
+		w := NewCSVWriter(&buf)
+		// A trailing cell keeps rows of one empty cell from reading back as
+		// blank lines
+		err := w.WriteRow([]any{tt.cell, "end"})
+		if err != nil {
+			t.Fatalf("WriteRow(%#v) error: %v", tt.cell, err)
+		}
+		err = w.Close()
+		if err != nil {
+			t.Fatalf("Close error: %v", err)
+		}
+
+		records, err := csv.NewReader(&buf).ReadAll()
+		if err != nil {
+			t.Fatalf("reading back %#v: %v", tt.cell, err)
+		}
+		if len(records) != 1 || len(records[0]) != 2 {
+			t.Fatalf("WriteRow(%#v) wrote %v, want one row of two cells", tt.cell, records)
+		}
+		if records[0][0] != tt.want {
+			t.Errorf("WriteRow(%#v) wrote %q, want %q", tt.cell, records[0][0], tt.want)
+		}
+	}
+}
+
+// xlsxCell is a cell of the generated sheet, as read back.
+type xlsxCell struct {
+	Ref    string `xml:"r,attr"`
+	Type   string `xml:"t,attr"`
+	Style  string `xml:"s,attr"`
+	Value  string `xml:"v"`
+	Inline string `xml:"is>t"`
+}
+
+func readXLSXSheet(t *testing.T, data []byte) [][]xlsxCell {
+	t.Helper()
+	zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		t.Fatalf("reading workbook: %v", err)
+	}
+
+	var sheet struct {
+		Rows []struct {
+			Cells []xlsxCell `xml:"c"`
+		} `xml:"sheetData>row"`
+	}
+	for _, f := range zr.File {
+		if f.Name != "xl/worksheets/sheet1.xml" {
+			continue
+		}
+		rc, err := f.Open()
+		if err != nil {
+			t.Fatalf("opening sheet: %v", err)
+		}
+		defer rc.Close()
+		content, err := io.ReadAll(rc)
+		if err != nil {
+			t.Fatalf("reading sheet: %v", err)
+		}
+		err = xml.Unmarshal(content, &sheet)
+		if err != nil {
+			t.Fatalf("sheet is not valid XML: %v", err)
+		}
+
+		rows := make([][]xlsxCell, len(sheet.Rows))
+		for i, row := range sheet.Rows {
+			rows[i] = row.Cells
+		}
+		return rows
+	}
+	t.Fatal("workbook has no sheet")
+	return nil
+}
+
+func TestXLSXWriter(t *testing.T) {
+	var buf bytes.Buffer
+	w, err := NewXLSXWriter(&buf, `Q&A <2024>`)
+	if err != nil {
+		t.Fatalf("NewXLSXWriter error: %v", err)
+	}
+
+	wide := make([]any, 28)
+	wide[27] = "last"
+	rows := [][]any{
+		{"<b>Tom & \"Jerry\"</b>", "=1+1", nil, 42, int64(7), 1.5},
+		{time.Date(1900, 1, 1, 12, 0, 0, 0, time.UTC)},
+		wide,
+	}
+	for _, row := range rows {
+		err = w.WriteRow(row)
+		if err != nil {
+			t.Fatalf("WriteRow error: %v", err)
+		}
+	}
+	err = w.Close()
+	if err != nil {
+		t.Fatalf("Close error: %v", err)
+	}
+
+	sheet := readXLSXSheet(t, buf.Bytes())
+	if len(sheet) != 3 {
+		t.Fatalf("sheet has %d rows, want 3", len(sheet))
+	}
+
+	want := []xlsxCell{
+		{Ref: "A1", Type: "inlineStr", Inline: "<b>Tom & \"Jerry\"</b>"},
+		{Ref: "B1", Type: "inlineStr", Inline: "=1+1"},
+		{Ref: "D1", Value: "42"},
+		{Ref: "E1", Value: "7"},
+		{Ref: "F1", Value: "1.5"},
+	}
+	if len(sheet[0]) != len(want) {
+		t.Fatalf("first row has cells %v, want %v", sheet[0], want)
+	}
+	for i, cell := range want {
+		if sheet[0][i] != cell {
+			t.Errorf("cell %d = %+v, want %+v", i, sheet[0][i], cell)
+		}
+	}
+
+	// 1900-01-01 is serial 2, as spreadsheets count the missing 1900-02-29
+	date := xlsxCell{Ref: "A2", Style: "1", Value: "2.5"}
+	if len(sheet[1]) != 1 || sheet[1][0] != date {
+		t.Errorf("date row = %+v, want %+v", sheet[1], date)
+	}
+
+	last := xlsxCell{Ref: "AB3", Type: "inlineStr", Inline: "last"}
+	if len(sheet[2]) != 1 || sheet[2][0] != last {
+		t.Errorf("wide row = %+v, want %+v", sheet[2], last)
+	}
+}
+
+func TestXLSXWriterEscapesSheetName(t *testing.T) {
+	var buf bytes.Buffer
+	w, err := NewXLSXWriter(&buf, `Q&A <2024>`)
+	if err != nil {
+		t.Fatalf("NewXLSXWriter error: %v", err)
+	}
+	err = w.Close()
+	if err != nil {
+		t.Fatalf("Close error: %v", err)
+	}
+
+	zr, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatalf("reading workbook: %v", err)
+	}
+	for _, f := range zr.File {
+		if f.Name != "xl/workbook.xml" {
+			continue
+		}
+		rc, err := f.Open()
+		if err != nil {
+			t.Fatalf("opening workbook part: %v", err)
+		}
+		defer rc.Close()
+
+		var workbook struct {
+			Sheets []struct {
+				Name string `xml:"name,attr"`
+			} `xml:"sheets>sheet"`
+		}
+		err = xml.NewDecoder(rc).Decode(&workbook)
+		if err != nil {
+			t.Fatalf("workbook is not valid XML: %v", err)
+		}
+		if len(workbook.Sheets) != 1 || workbook.Sheets[0].Name != "Q&A <2024>" {
+			t.Errorf("sheets = %+v, want one named %q", workbook.Sheets, "Q&A <2024>")
+		}
+		return
+	}
+	t.Fatal("workbook part missing")
+}
+
+func TestXLSXWriterRejectsUnsupportedCells(t *testing.T) {
+	w, err := NewXLSXWriter(io.Discard, "Sheet")
+	if err != nil {
+		t.Fatalf("NewXLSXWriter error: %v", err)
+	}
+	err = w.WriteRow([]any{true})
+	if err == nil || !strings.Contains(err.Error(), "unsupported cell type") {
+		t.Errorf("WriteRow(bool) error = %v, want unsupported cell type", err)
+	}
+}
@@ -0,0 +1,65 @@
+package utils
+
+import (
+	"errors"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const ticketTokenType = "ticket"
+
+// GenerateTicketCode signs the code printed on a registration's ticket. It is
+// signed like an access token but carries a type claim, so VerifyToken
+// refuses it. Ticket codes do not expire; whether one is still good is
+// decided by its registration at check-in.
+func GenerateTicketCode(registrationID, eventID int64) (string, error) {
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+		"type":            ticketTokenType,
+		"registration_id": registrationID,
+		"event_id":        eventID,
+	})
+
+	secretKey := getSecretKey()
+	return token.SignedString([]byte(secretKey))
+}
+
+func VerifyTicketCode(code string) (registrationID, eventID int64, err error) {
+	token, err := jwt.Parse(code, func(token *jwt.Token) (any, error) {
+		_, ok := token.Method.(*jwt.SigningMethodHMAC)
+		if !ok {
+			return nil, errors.New("unexpected signing method")
+		}
+
+		secretKey := getSecretKey()
+		return []byte(secretKey), nil
+	})
+
+	if err != nil {
+		return 0, 0, err
+	}
+
+	if !token.Valid {
+		return 0, 0, errors.New("invalid ticket code")
+	}
+
+	claims, ok := token.Claims.(jwt.MapClaims)
+	if !ok {
+		return 0, 0, errors.New("invalid ticket claims")
+	}
+
+	if claims["type"] != ticketTokenType {
+		return 0, 0, errors.New("not a ticket code")
+	}
+
+	floatRegistrationID, ok := claims["registration_id"].(float64)
+	if !ok {
+		return 0, 0, errors.New("invalid registration_id in ticket code")
+	}
+
+	floatEventID, ok := claims["event_id"].(float64)
+	if !ok {
+		return 0, 0, errors.New("invalid event_id in ticket code")
+	}
+
+	return int64(floatRegistrationID), int64(floatEventID), nil
+}
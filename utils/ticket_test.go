@@ -0,0 +1,95 @@
+package utils
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+func TestTicketCodeRoundTrip(t *testing.T) {
+	t.Setenv("JWT_SECRET", "test-secret")
+
+	code, err := GenerateTicketCode(12, 34)
+	if err != nil {
+		t.Fatalf("GenerateTicketCode error: %v", err)
+	}
+
+	registrationID, eventID, err := VerifyTicketCode(code)
+	if err != nil {
+		t.Fatalf("VerifyTicketCode error: %v", err)
+	}
+	if registrationID != 12 || eventID != 34 {
+		t.Errorf("VerifyTicketCode = (%d, %d), want (12, 34)", registrationID, eventID)
+	}
+}
+
+func TestVerifyTicketCodeRejectsTamperedCodes(t *testing.T) {
+	t.Setenv("JWT_SECRET", "test-secret")
+
+	code, err := GenerateTicketCode(12, 34)
+	if err != nil {
+		t.Fatalf("GenerateTicketCode error: %v", err)
+	}
+	forged, err := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+		"type":            ticketTokenType,
+		"registration_id": 12,
+		"event_id":        34,
+	}).SignedString([]byte("another-secret"))
+	if err != nil {
+		t.Fatalf("signing forged code: %v", err)
+	}
+
+	// Swap in the payload of another registration, keeping the signature
+	other, err := GenerateTicketCode(13, 34)
+	if err != nil {
+		t.Fatalf("GenerateTicketCode error: %v", err)
+	}
+	parts := strings.Split(code, ".")
+	otherParts := strings.Split(other, ".")
+	swapped := parts[0] + "." + otherParts[1] + "." + parts[2]
+
+	tests := []struct {
+		name string
+		code string
+	}{
+		{"swapped payload", swapped},
+		{"truncated signature", code[:len(code)-2]},
+		{"wrong key", forged},
+		{"unsigned", strings.Join(parts[:2], ".") + "."},
+		{"garbage", "not-a-ticket"},
+		{"empty", ""},
+	}
+	for _, tt := range tests {
+		_, _, err := VerifyTicketCode(tt.code)
+		if err == nil {
+			t.Errorf("%s: VerifyTicketCode accepted %q", tt.name, tt.code)
+		}
+	}
+}
+
+func TestVerifyTicketCodeRejectsAccessTokens(t *testing.T) {
+	t.Setenv("JWT_SECRET", "test-secret")
+
+	token, err := GenerateToken("ada@example.com", 12, "user", 0)
+	if err != nil {
+		t.Fatalf("GenerateToken error: %v", err)
+	}
+	_, _, err = VerifyTicketCode(token)
+	if err == nil {
+		t.Error("VerifyTicketCode accepted an access token")
+	}
+}
+
+func TestVerifyTokenRejectsTicketCodes(t *testing.T) {
+	t.Setenv("JWT_SECRET", "test-secret")
+
+	code, err := GenerateTicketCode(12, 34)
+	if err != nil {
+		t.Fatalf("GenerateTicketCode error: %v", err)
+	}
+	_, err = VerifyToken(code)
+	if err == nil {
+		t.Error("VerifyToken accepted a ticket code")
+	}
+}
@@ -0,0 +1,66 @@
+package utils
+
+import (
+	"errors"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const emailVerificationTokenType = "email_verification"
+
+// GenerateEmailVerificationToken signs the token in an email verification
+// link. It names the address it was sent to, so it stops working if the user
+// changes their email in the meantime.
+func GenerateEmailVerificationToken(userID int64, email string, ttl time.Duration) (string, error) {
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+		"type":    emailVerificationTokenType,
+		"user_id": userID,
+		"email":   email,
+		"exp":     time.Now().Add(ttl).Unix(),
+	})
+
+	secretKey := getSecretKey()
+	return token.SignedString([]byte(secretKey))
+}
+
+func VerifyEmailVerificationToken(tokenString string) (userID int64, email string, err error) {
+	token, err := jwt.Parse(tokenString, func(token *jwt.Token) (any, error) {
+		_, ok := token.Method.(*jwt.SigningMethodHMAC)
+		if !ok {
+			return nil, errors.New("unexpected signing method")
+		}
+
+		secretKey := getSecretKey()
+		return []byte(secretKey), nil
+	}, jwt.WithExpirationRequired())
+
+	if err != nil {
+		return 0, "", err
+	}
+
+	if !token.Valid {
+		return 0, "", errors.New("invalid verification token")
+	}
+
+	claims, ok := token.Claims.(jwt.MapClaims)
+	if !ok {
+		return 0, "", errors.New("invalid verification token claims")
+	}
+
+	if claims["type"] != emailVerificationTokenType {
+		return 0, "", errors.New("not an email verification token")
+	}
+
+	floatUserID, ok := claims["user_id"].(float64)
+	if !ok {
+		return 0, "", errors.New("invalid user_id in verification token")
+	}
+
+	email, ok = claims["email"].(string)
+	if !ok {
+		return 0, "", errors.New("invalid email in verification token")
+	}
+
+	return int64(floatUserID), email, nil
+}